@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"course/config"
 	_ "course/config"
 	"course/exps"
+	"course/optimizer"
+	"course/optimizer/multistart"
+	"course/payroll"
 	_ "course/pkg/clock"
+	"course/pkg/random"
 	"course/presenter"
 	"course/scene"
 	"course/stats"
+	"course/trace"
+	"course/validate"
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"time"
 )
 
 func main() {
@@ -21,9 +30,15 @@ func main() {
 func runExp() {
 	pres := new(presenter.Presenter)
 
-	st := stats.NewDriversStats()
+	master := random.New(config.C().Seed)
+	slog.Info("experiments", slog.Uint64("seed", master.Seed()))
 
-	for name := range exps.Optimizers() {
+	st := stats.NewDriversStats(master.Seed())
+
+	budget := time.Duration(config.C().OptimizerTimeoutSec) * time.Second
+	pay := payroll.FromConfig(config.C().Payroll)
+
+	for _, name := range exps.Names() {
 		err := os.Mkdir(fmt.Sprintf("exps/output/%s", name), 0777)
 		if err != nil {
 			if errors.Is(err, os.ErrExist) {
@@ -34,14 +49,95 @@ func runExp() {
 	}
 
 	for expCount := 0; expCount < config.C().ExperimentsCount; expCount++ {
-		ttBuilder, dhBuilder, bsBuilder := scene.GenScene()
-		for k, opt := range exps.Optimizers() {
-			tt, dh, bs := ttBuilder.Build(), dhBuilder.Build(), bsBuilder.Build()
-			opt.Optimize(tt, bs, dh)
-			st.Collect(tt, dh, bs, k)
+		expSeed := master.Uint64()
+		if config.C().ExperimentSeed != 0 {
+			expSeed = config.C().ExperimentSeed
+		}
+		slog.Info("experiment", slog.Int("experiment", expCount+1), slog.Uint64("seed", expSeed))
+
+		ttBuilder, depots := scene.GenScene(random.New(expSeed))
+		dhBuilder, bsBuilder := depots.Pool()
+		sc := multistart.Scene{TT: ttBuilder, Drivers: dhBuilder, Buses: bsBuilder}
+		for _, k := range exps.Names() {
+			// у каждого оптимизатора свое зерно, чтобы его результат
+			// не зависел от того, какие оптимизаторы запускались до него
+			runSeed := random.Derive(expSeed, k)
+			dhBuilder.SetSeed(runSeed)
+			bsBuilder.SetSeed(runSeed)
+			opt := exps.Optimizers(sc, runSeed)[k]
+			tt, dh, bs := scene.Timetable(ttBuilder), dhBuilder.Build(), bsBuilder.Build()
+
+			rec := trace.NewRecorder()
+			ctx, cancel := context.WithTimeout(context.Background(), budget)
+			ctx = optimizer.WithObserver(ctx, rec.Observe)
+			res, err := opt.Optimize(ctx, tt, bs, dh)
+			cancel()
+			if rec.Len() > 0 {
+				if err := rec.Save(fmt.Sprintf("exps/output/%s/%d_trace", k, expCount+1)); err != nil {
+					slog.Error("trace", slog.String("name", k), slog.String("error", err.Error()))
+				}
+			}
+			if err != nil {
+				slog.Error(
+					"optimizer",
+					slog.String("name", k),
+					slog.Int("experiment", expCount+1),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			slog.Info(
+				"optimizer",
+				slog.String("name", k),
+				slog.Int("experiment", expCount+1),
+				slog.String("status", res.Status.String()),
+				slog.Float64("objective", res.Objective),
+				slog.Int("drivers", res.Drivers),
+				slog.Int("buses", res.Buses),
+				slog.Int("iterations", res.Iterations),
+				slog.Duration("wall_time", res.WallTime),
+			)
+			if gap, ok := res.Gap(); ok {
+				slog.Info(
+					"optimizer",
+					slog.String("name", k),
+					slog.Int("experiment", expCount+1),
+					slog.Float64("lower_bound", res.LowerBound),
+					slog.Float64("gap", gap),
+				)
+			}
+			if res.Spread.Runs > 1 {
+				slog.Info(
+					"optimizer",
+					slog.String("name", k),
+					slog.Int("experiment", expCount+1),
+					slog.Int("runs", res.Spread.Runs),
+					slog.Float64("best", res.Spread.Best),
+					slog.Float64("worst", res.Spread.Worst),
+					slog.Float64("mean", res.Spread.Mean),
+					slog.Float64("stddev", res.Spread.StdDev),
+				)
+			}
+
+			pr := payroll.Compute(pay, tt, dh)
+			if err := pr.Save(fmt.Sprintf("exps/output/%s/%d_payroll", k, expCount+1)); err != nil {
+				slog.Error("payroll", slog.String("name", k), slog.String("error", err.Error()))
+			}
+
+			if vs := validate.Validate(tt, dh, bs); len(vs) > 0 {
+				slog.Warn(
+					"validate",
+					slog.String("name", k),
+					slog.Int("experiment", expCount+1),
+					slog.Int("violations", len(vs)),
+					slog.String("first", vs[0].String()),
+				)
+			} else {
+				st.Collect(tt, dh, bs, depots.Split(tt, bs, dh), pr, k, expSeed)
+			}
 
 			if expCount%10 == 0 {
-				pres.Present(fmt.Sprintf("exps/output/%s/%d", k, expCount+1), tt, dh, bs)
+				pres.Present(fmt.Sprintf("exps/output/%s/%d", k, expCount+1), expSeed, tt, dh, bs)
 			}
 		}
 
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"course/config"
+	"course/optimizer/vsp"
+	"course/pkg/path"
+	"course/pkg/random"
+	"course/repair"
+	"course/scene"
+	"course/validate"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"strings"
+	"time"
+)
+
+// Пример ремонта: строит расписание сцены оптимизатором vsp, устраивает сбой
+// в момент -at и печатает изменения, которые сделал repair.Repair.
+func main() {
+	seed := flag.Uint64("seed", config.C().Seed, "зерно сцены, 0 - случайное")
+	kind := flag.String("kind", "driver", "вид сбоя: driver, bus или trip")
+	at := flag.String("at", "12:00", "время сбоя, ЧЧ:ММ")
+	flag.Parse()
+
+	rnd := random.New(*seed)
+	ttb, depots := scene.GenScene(rnd)
+	dhb, bsb := depots.Pool()
+	tt, dh, bs := scene.Timetable(ttb), dhb.Build(), bsb.Build()
+	if _, err := vsp.New().Optimize(context.Background(), tt, bs, dh); err != nil {
+		log.Fatal(err)
+	}
+
+	clockAt, err := time.Parse("15:04", *at)
+	if err != nil {
+		log.Fatal(err)
+	}
+	now := scene.Date().Add(time.Duration(clockAt.Hour())*time.Hour + time.Duration(clockAt.Minute())*time.Minute)
+
+	// сбой затрагивает первый путь, начинающийся после now
+	var next path.Path
+	for _, p := range tt.GetEach(func(p path.Path) bool { return !p.StartTime.Before(now) }) {
+		if next.ID == uuid.Nil || p.StartTime.Before(next.StartTime) ||
+			p.StartTime.Equal(next.StartTime) && strings.Compare(p.ID.String(), next.ID.String()) < 0 {
+			next = p
+		}
+	}
+	if next.ID == uuid.Nil {
+		log.Fatalf("no paths after %s", *at)
+	}
+
+	dis := repair.Disruption{From: now, DriverID: next.DriverID, BusID: next.BusID, PathID: next.ID}
+	switch *kind {
+	case "driver":
+		dis.Kind = repair.DriverUnavailable
+	case "bus":
+		dis.Kind = repair.BusOutOfService
+	case "trip":
+		dis.Kind = repair.TripCancelled
+	default:
+		log.Fatalf("unknown kind %q", *kind)
+	}
+
+	before := len(validate.Validate(tt, dh, bs))
+	changes, err := repair.Repair(tt, bs, dh, dis, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	after := len(validate.Validate(tt, dh, bs))
+
+	fmt.Printf("seed %d, %s at %s\n", rnd.Seed(), dis.Kind, now.Format("15:04"))
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	fmt.Printf("%d changes, violations %d -> %d\n", len(changes), before, after)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"course/config"
+	"course/optimizer/vsp"
+	"course/pkg/random"
+	"course/presenter"
+	"course/roster"
+	"course/scene"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// Пример графика: на каждый день периода строит сцену со своим зерном,
+// решает ее оптимизатором vsp и раскладывает дневные смены по водителям
+// штата через roster.Plan. Календарь сохраняется в -out.
+func main() {
+	seed := flag.Uint64("seed", config.C().Seed, "зерно периода, 0 - случайное")
+	out := flag.String("out", "exps/output/roster", "файл календаря без расширения")
+	flag.Parse()
+
+	cfg, err := roster.FromConfig(config.C().Roster)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Start.IsZero() {
+		cfg.Start = scene.Date()
+	}
+
+	master := random.New(*seed)
+	days := make([][]roster.Duty, cfg.Days)
+	for day := range days {
+		// у каждого дня свое расписание, и его сцена не зависит от других дней
+		rnd := random.New(random.Derive(master.Seed(), fmt.Sprintf("day%d", day)))
+		ttb, depots := scene.GenScene(rnd)
+		dhb, bsb := depots.Pool()
+		tt, dh, bs := scene.Timetable(ttb), dhb.Build(), bsb.Build()
+		if _, err := vsp.New().Optimize(context.Background(), tt, bs, dh); err != nil {
+			log.Fatal(err)
+		}
+		days[day] = roster.Duties(cfg.Start.AddDate(0, 0, day), tt, dh)
+	}
+
+	r := roster.Plan(cfg.Start, days)
+	new(presenter.Presenter).PresentRoster(*out, r)
+
+	duties := 0
+	for _, d := range days {
+		duties += len(d)
+	}
+	fmt.Printf("seed %d, %d days, %d duties, %d drivers, %d violations\n",
+		master.Seed(), cfg.Days, duties, len(r.Employees), len(r.Violations()))
+}
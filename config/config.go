@@ -2,8 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 var c *Config
@@ -11,21 +14,88 @@ var c *Config
 type Config struct {
 	ExperimentsCount int `json:"experiments_count"`
 
-	InitialDriverACount int `json:"initial_driver_a_count"`
-	InitialDriverBCount int `json:"initial_driver_b_count"`
+	// типы водителей; без них действуют типы по умолчанию
+	DriverTypes []DriverTypeConfig `json:"driver_types"`
 
 	InitialBusCount int `json:"initial_bus_count"`
 
 	InitialBusStationsCount int `json:"initial_bus_stations_count"`
 	DistinctPathCount       int `json:"distinct_path_count"`
 	TimeSeriesPathsCount    int `json:"time_series_paths_count"`
+
+	// зерно всех экспериментов; 0 - случайное. Зерна отдельных экспериментов
+	// выводятся из него, и каждый эксперимент можно повторить по своему зерну
+	Seed uint64 `json:"seed"`
+	// зерно одного эксперимента, которое нужно повторить; 0 - не задано
+	ExperimentSeed uint64 `json:"experiment_seed"`
+	// день расписания сцены в формате 2006-01-02; пусто - 2024-11-30
+	SceneDate string `json:"scene_date"`
+
+	// реализация расписания: ttv1 (по умолчанию) или индексированная ttv2
+	Timetable string `json:"timetable"`
+
+	// бюджет времени на один запуск оптимизатора
+	OptimizerTimeoutSec int `json:"optimizer_timeout_sec"`
+
+	Cost       CostConfig       `json:"cost"`
+	Genetic    GeneticConfig    `json:"genetic"`
+	Annealing  AnnealingConfig  `json:"annealing"`
+	Tabu       TabuConfig       `json:"tabu"`
+	Crew       CrewConfig       `json:"crew"`
+	Improve    ImproveConfig    `json:"improve"`
+	MultiStart MultiStartConfig `json:"multi_start"`
+	Roster     RosterConfig     `json:"roster"`
+	Payroll    PayrollConfig    `json:"payroll"`
+}
+
+// CostConfig - веса модели стоимости, незаданные берутся по умолчанию
+type CostConfig struct {
+	Bus           float64 `json:"bus"`
+	VehicleHour   float64 `json:"vehicle_hour"`
+	OvertimeHour  float64 `json:"overtime_hour"`
+	RestViolation float64 `json:"rest_violation"`
+	IdleHour      float64 `json:"idle_hour"`
+	Uncovered     float64 `json:"uncovered"`
+}
+
+// DriverTypeConfig - тип водителя, незаданные поля берутся по умолчанию
+type DriverTypeConfig struct {
+	Name             string  `json:"name"`
+	WorkDurMin       int     `json:"work_dur_min"`
+	RestDurMin       int     `json:"rest_dur_min"`
+	RestCount        int64   `json:"rest_count"`
+	ContinuousDurMin int     `json:"continuous_dur_min"`
+	WorkDays         int     `json:"work_days"`
+	WeekendDays      int     `json:"weekend_days"`
+	MinDailyRestMin  int     `json:"min_daily_rest_min"`
+	WeeklyCapMin     int     `json:"weekly_cap_min"`
+	HourlyCost       float64 `json:"hourly_cost"`
+	// встроенный профиль правил, например "eu561"
+	Profile string `json:"profile"`
+	// правила смены; добавляются к профилю, а без профиля и правил
+	// правила выводятся из полей выше
+	Rules []RuleConfig `json:"rules"`
+	// сколько водителей этого типа в штате сцены с самого начала
+	InitialCount int `json:"initial_count"`
+}
+
+// RuleConfig - трудовое правило типа водителя. Rule - имя правила:
+// no_overlap, max_spread (limit_min), max_continuous (limit_min, break_min),
+// min_break (break_min после limit_min вождения), max_breaks (count, break_min),
+// min_daily_rest (limit_min), а также правила профиля eu561 без параметров:
+// eu_break, eu_daily_driving, eu_weekly_driving, eu_fortnight_driving.
+type RuleConfig struct {
+	Rule     string `json:"rule"`
+	LimitMin int    `json:"limit_min"`
+	BreakMin int    `json:"break_min"`
+	Count    int    `json:"count"`
 }
 
 func C() *Config { return c }
 
 func init() {
 	c = new(Config)
-	f, err := os.Open("config.json")
+	f, err := open("config.json")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,3 +106,94 @@ func init() {
 		log.Fatal(err)
 	}
 }
+
+// open ищет файл name в текущем каталоге, а если его там нет - выше:
+// тесты пакета запускаются из его каталога, а конфигурация лежит в корне модуля
+func open(name string) (*os.File, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		f, err := os.Open(filepath.Join(dir, name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, err
+		}
+		dir = parent
+	}
+}
+
+// GeneticConfig - параметры генетического алгоритма, незаданные берутся по умолчанию
+type GeneticConfig struct {
+	Population     int     `json:"population"`
+	Generations    int     `json:"generations"`
+	TournamentSize int     `json:"tournament_size"`
+	Elite          int     `json:"elite"`
+	CrossoverRate  float64 `json:"crossover_rate"`
+	SwapRate       float64 `json:"swap_rate"`
+	RelocateRate   float64 `json:"relocate_rate"`
+	// 0 - случайное зерно
+	Seed uint64 `json:"seed"`
+}
+
+// AnnealingConfig - параметры имитации отжига, незаданные берутся по умолчанию
+type AnnealingConfig struct {
+	Iterations int     `json:"iterations"`
+	StartTemp  float64 `json:"start_temp"`
+	EndTemp    float64 `json:"end_temp"`
+	// geometric или linear
+	Cooling string `json:"cooling"`
+	// 0 - случайное зерно
+	Seed uint64 `json:"seed"`
+}
+
+// TabuConfig - параметры поиска с запретами, незаданные берутся по умолчанию
+type TabuConfig struct {
+	Iterations int `json:"iterations"`
+	Tenure     int `json:"tenure"`
+	Candidates int `json:"candidates"`
+	// 0 - случайное зерно
+	Seed uint64 `json:"seed"`
+}
+
+// CrewConfig - ограничения перебора смен, незаданные берутся по умолчанию
+type CrewConfig struct {
+	MaxSuccessors    int `json:"max_successors"`
+	MaxDutiesPerTrip int `json:"max_duties_per_trip"`
+}
+
+// ImproveConfig - параметры локального поиска после оптимизатора, незаданные берутся по умолчанию
+type ImproveConfig struct {
+	MaxRounds int `json:"max_rounds"`
+	FewTrips  int `json:"few_trips"`
+}
+
+// MultiStartConfig - параметры параллельного мультистарта, незаданные берутся по умолчанию
+type MultiStartConfig struct {
+	Starts int `json:"starts"`
+	// 0 - случайное зерно
+	Seed uint64 `json:"seed"`
+}
+
+// RosterConfig - параметры графика работы на несколько дней, незаданные берутся по умолчанию
+type RosterConfig struct {
+	Days int `json:"days"`
+	// первый день графика, ГГГГ-ММ-ДД; пусто - день сцены scene_date
+	StartDate string `json:"start_date"`
+}
+
+// PayrollConfig - правила оплаты труда, незаданные берутся по умолчанию
+type PayrollConfig struct {
+	OvertimeMultiplier float64 `json:"overtime_multiplier"`
+	// доля часовой ставки
+	NightPremium float64 `json:"night_premium"`
+	// часы суток 0-23; nil - по умолчанию, чтобы полночь тоже можно было задать
+	NightStartHour *int `json:"night_start_hour"`
+	NightEndHour   *int `json:"night_end_hour"`
+	// гарантированная оплата смены в минутах работы
+	MinGuaranteedMin int `json:"min_guaranteed_min"`
+}
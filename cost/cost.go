@@ -0,0 +1,151 @@
+package cost
+
+import (
+	"course/config"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+)
+
+var m *Model
+
+// M - общая для всех оптимизаторов и статистики модель стоимости
+func M() *Model { return m }
+
+func init() {
+	m = New(FromConfig(config.C().Cost))
+}
+
+// Weights - веса составляющих стоимости расписания
+type Weights struct {
+	// стоимость одного автобуса
+	Bus float64
+	// за каждый час работы автобуса от выезда из депо до возврата
+	VehicleHour float64
+	// за каждый час сверх WorkDur
+	OvertimeHour float64
+	// за каждый отрезок вождения без положенного перерыва
+	RestViolation float64
+	// за каждый час простоя водителя внутри смены
+	IdleHour float64
+	// за каждый путь без водителя или автобуса
+	Uncovered float64
+}
+
+func Default() Weights {
+	return Weights{
+		Bus:           500,
+		VehicleHour:   20,
+		OvertimeHour:  500,
+		RestViolation: 250,
+		IdleHour:      10,
+		Uncovered:     5000,
+	}
+}
+
+// FromConfig берет веса из конфигурации, незаданные веса остаются по умолчанию
+func FromConfig(c config.CostConfig) Weights {
+	w := Default()
+	set := func(dst *float64, v float64) {
+		if v != 0 {
+			*dst = v
+		}
+	}
+	set(&w.Bus, c.Bus)
+	set(&w.VehicleHour, c.VehicleHour)
+	set(&w.OvertimeHour, c.OvertimeHour)
+	set(&w.RestViolation, c.RestViolation)
+	set(&w.IdleHour, c.IdleHour)
+	set(&w.Uncovered, c.Uncovered)
+	return w
+}
+
+// Score - стоимость расписания по составляющим
+type Score struct {
+	Drivers        float64
+	Buses          float64
+	VehicleHours   float64
+	Overtime       float64
+	RestViolations float64
+	Idle           float64
+	Uncovered      float64
+}
+
+func (s Score) Total() float64 {
+	return s.Drivers + s.Buses + s.VehicleHours + s.Overtime + s.RestViolations + s.Idle + s.Uncovered
+}
+
+type Model struct {
+	w Weights
+}
+
+func New(w Weights) *Model {
+	return &Model{w: w}
+}
+
+func (m *Model) Weights() Weights { return m.w }
+
+// Score оценивает готовое расписание: чем меньше, тем лучше
+func (m *Model) Score(
+	tt timetable.Timetable,
+	dh *driverhub.DriverHub,
+	bs *station.BusStation,
+) Score {
+	var s Score
+
+	byDriver := make(map[uuid.UUID][]path.Path)
+	for _, p := range tt.GetEach(func(p path.Path) bool { return true }) {
+		if !p.IsPlanned() {
+			s.Uncovered += m.w.Uncovered
+		}
+		if p.DriverID != uuid.Nil {
+			byDriver[p.DriverID] = append(byDriver[p.DriverID], p)
+		}
+	}
+
+	// водители суммируются в постоянном порядке, чтобы итог не зависел
+	// от порядка обхода map даже в последнем знаке
+	drivers := dh.Drivers()
+	ids := make([]uuid.UUID, 0, len(drivers))
+	for id := range drivers {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	for _, id := range ids {
+		s.add(m.Driver(drivers[id], byDriver[id]))
+	}
+
+	s.Buses = float64(len(bs.Buses())) * m.w.Bus
+	for _, blk := range bs.Blocks(tt) {
+		s.VehicleHours += blk.Duration().Hours() * m.w.VehicleHour
+	}
+
+	return s
+}
+
+// Driver оценивает одного водителя с его путями
+func (m *Model) Driver(d driver.Driver, ps []path.Path) Score {
+	var s Score
+	s.Drivers = d.Spec().ShiftCost()
+
+	shift := driver.NewShift(d, ps)
+	s.Overtime = shift.Overtime().Hours() * m.w.OvertimeHour
+	s.RestViolations = float64(shift.RestViolations()) * m.w.RestViolation
+	s.Idle = shift.Idle().Hours() * m.w.IdleHour
+	return s
+}
+
+func (s *Score) add(o Score) {
+	s.Drivers += o.Drivers
+	s.Buses += o.Buses
+	s.VehicleHours += o.VehicleHours
+	s.Overtime += o.Overtime
+	s.RestViolations += o.RestViolations
+	s.Idle += o.Idle
+	s.Uncovered += o.Uncovered
+}
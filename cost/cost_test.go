@@ -0,0 +1,113 @@
+package cost
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"math"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// trip - путь номер n с from до to (часы и минуты дня day)
+func trip(n int, from, to [2]int) path.Path {
+	at := func(hm [2]int) time.Time {
+		return day.Add(time.Duration(hm[0])*time.Hour + time.Duration(hm[1])*time.Minute)
+	}
+	return path.Path{
+		ID:        uuid.UUID{byte(n)},
+		Number:    n,
+		Points:    []path.Point{{Id: uuid.UUID{0xa0}, IsBusStation: true}, {Id: uuid.UUID{0xb0}, IsBusStation: true}},
+		StartTime: at(from),
+		EndTime:   at(to),
+	}
+}
+
+func TestScore(t *testing.T) {
+	w := Weights{
+		Bus:           500,
+		VehicleHour:   40,
+		OvertimeHour:  500,
+		RestViolation: 250,
+		IdleHour:      60,
+		Uncovered:     5000,
+	}
+
+	// водитель A (смена 8 часов, перерыв час, без перерыва не больше 4 часов):
+	// 06:00-11:00 и 11:20-12:00 - 5ч40м без перерыва, затем перерыв
+	// и 13:00-15:00. Смена 9 часов - час сверхурочно, вождение 7ч40м,
+	// один положенный перерыв, простой 20 минут.
+	// Водитель B ведет один путь без простоя, один путь никто не ведет.
+	// Все назначенные пути идут на первом автобусе: из депо D к началу A
+	// полчаса, от конца B через A обратно 35 минут, блок 05:30-15:35.
+	// Второй автобус стоит в депо.
+	a, b := driver.New(uuid.UUID{0xd1}, "A"), driver.New(uuid.UUID{0xd2}, "B")
+	plan := []struct {
+		p   path.Path
+		drv driver.Driver
+	}{
+		{trip(1, [2]int{6, 0}, [2]int{11, 0}), a},
+		{trip(2, [2]int{11, 20}, [2]int{12, 0}), a},
+		{trip(3, [2]int{13, 0}, [2]int{15, 0}), a},
+		{trip(4, [2]int{7, 0}, [2]int{8, 0}), b},
+		{trip(5, [2]int{9, 0}, [2]int{10, 0}), nil},
+	}
+
+	depot := path.Point{Id: uuid.UUID{0xd0}, IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for _, pl := range plan {
+		ttb.AddPath(pl.p, []path.DstItem{{From: depot.Id, To: uuid.UUID{0xa0}, Dur: 30 * time.Minute}})
+	}
+	tt := ttb.Build()
+	dhb := driverhub.NewDriverHubBuilder()
+	dhb.AddDriver(a)
+	dhb.AddDriver(b)
+	bsb := station.NewBusStationBuilder()
+	bsb.SetDepot(depot)
+	bus1, bus2 := bus.NewBus(uuid.UUID{0xc1}), bus.NewBus(uuid.UUID{0xc2})
+	bsb.AddBus(bus1)
+	bsb.AddBus(bus2)
+	for _, pl := range plan {
+		if pl.drv == nil {
+			continue
+		}
+		tt.AssignDriverToPath(pl.p.ID, pl.drv.ID())
+		tt.AssignBusToPath(pl.p.ID, bus1.ID)
+	}
+
+	got := New(w).Score(tt, dhb.Build(), bsb.Build())
+	want := Score{
+		// смена оплачивается целиком по ставке типа
+		Drivers:        a.Spec().ShiftCost() + b.Spec().ShiftCost(),
+		Buses:          2 * 500,
+		VehicleHours:   (10 + 5.0/60) * 40,
+		Overtime:       1 * 500,
+		RestViolations: 1 * 250,
+		Idle:           20.0 / 60 * 60,
+		Uncovered:      1 * 5000,
+	}
+	terms := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Drivers", got.Drivers, want.Drivers},
+		{"Buses", got.Buses, want.Buses},
+		{"VehicleHours", got.VehicleHours, want.VehicleHours},
+		{"Overtime", got.Overtime, want.Overtime},
+		{"RestViolations", got.RestViolations, want.RestViolations},
+		{"Idle", got.Idle, want.Idle},
+		{"Uncovered", got.Uncovered, want.Uncovered},
+		{"Total", got.Total(), want.Total()},
+	}
+	for _, tc := range terms {
+		if math.Abs(tc.got-tc.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
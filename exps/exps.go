@@ -1,17 +1,73 @@
 package exps
 
 import (
+	"course/config"
 	"course/optimizer"
+	"course/optimizer/annealing"
+	"course/optimizer/bnb"
 	"course/optimizer/bruteforce"
+	"course/optimizer/crew"
 	"course/optimizer/gen_algorithm"
 	"course/optimizer/greedy"
+	"course/optimizer/improve"
+	"course/optimizer/multistart"
+	"course/optimizer/tabu"
+	"course/optimizer/vsp"
+	"slices"
 )
 
-func Optimizers() map[string]optimizer.Optimizer {
+// Optimizers - оптимизаторы эксперимента. Сцена sc нужна мультистарту,
+// который сам собирает из нее копии расписания для параллельных запусков.
+// seed - зерно для всех случайных оптимизаторов, у которых в конфигурации
+// свое зерно не задано.
+func Optimizers(sc multistart.Scene, seed uint64) map[string]optimizer.Optimizer {
+	gaCfg := gen_algorithm.FromConfig(config.C().Genetic)
+	saCfg := annealing.FromConfig(config.C().Annealing)
+	tsCfg := tabu.FromConfig(config.C().Tabu)
+	crCfg := crew.FromConfig(config.C().Crew)
+	lsCfg := improve.FromConfig(config.C().Improve)
+	msCfg := multistart.FromConfig(config.C().MultiStart)
+	if gaCfg.Seed == 0 {
+		gaCfg.Seed = seed
+	}
+	if saCfg.Seed == 0 {
+		saCfg.Seed = seed
+	}
+	if tsCfg.Seed == 0 {
+		tsCfg.Seed = seed
+	}
+	if msCfg.Seed == 0 {
+		msCfg.Seed = seed
+	}
 	return map[string]optimizer.Optimizer{
-		"bruteforce":       bruteforce.NewBrutForceOptimizer(),
+		"bruteforce":       bruteforce.NewBrutForceOptimizer(seed),
 		"greedy":           greedy.NewGreedyOptimizer(),
-		"gen_algorithm_bf": gen_algorithm.New(bruteforce.NewBrutForceOptimizer()),
-		"gen_algorithm_gr": gen_algorithm.New(greedy.NewGreedyOptimizer()),
+		"greedy_ls":        improve.New(greedy.NewGreedyOptimizer(), lsCfg),
+		"gen_algorithm_bf": gen_algorithm.New(bruteforce.NewBrutForceOptimizer(seed), gaCfg),
+		"gen_algorithm_gr": gen_algorithm.New(greedy.NewGreedyOptimizer(), gaCfg),
+		"annealing_gr":     annealing.New(greedy.NewGreedyOptimizer(), saCfg),
+		"tabu_gr":          tabu.New(greedy.NewGreedyOptimizer(), tsCfg),
+		"vsp":              vsp.New(),
+		"vsp_ls":           improve.New(vsp.New(), lsCfg),
+		"crew_vsp":         crew.New(vsp.New(), crCfg),
+		"bnb":              bnb.New(),
+		"multistart_bf": multistart.New(sc, func(seed uint64) optimizer.Optimizer {
+			return bruteforce.NewBrutForceOptimizer(seed)
+		}, msCfg),
+		"multistart_annealing_gr": multistart.New(sc, func(seed uint64) optimizer.Optimizer {
+			cfg := saCfg
+			cfg.Seed = seed
+			return annealing.New(greedy.NewGreedyOptimizer(), cfg)
+		}, msCfg),
+	}
+}
+
+// Names - имена оптимизаторов в постоянном порядке
+func Names() []string {
+	names := make([]string, 0)
+	for name := range Optimizers(multistart.Scene{}, 0) {
+		names = append(names, name)
 	}
+	slices.Sort(names)
+	return names
 }
@@ -0,0 +1,153 @@
+package annealing
+
+import (
+	"context"
+	"course/config"
+	"course/optimizer"
+	"course/optimizer/localsearch"
+	"course/pkg/driverhub"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Schedule - температура на шаге step из total
+type Schedule func(step, total int) float64
+
+// Geometric - температура убывает в геометрической прогрессии от from до to
+func Geometric(from, to float64) Schedule {
+	return func(step, total int) float64 {
+		return from * math.Pow(to/from, float64(step)/float64(max(total, 1)))
+	}
+}
+
+// Linear - температура убывает линейно от from до to
+func Linear(from, to float64) Schedule {
+	return func(step, total int) float64 {
+		return from + (to-from)*float64(step)/float64(max(total, 1))
+	}
+}
+
+// Config - параметры имитации отжига
+type Config struct {
+	Iterations int
+	Schedule   Schedule
+	// 0 - случайное зерно
+	Seed uint64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Iterations: 5000,
+		Schedule:   Geometric(5000, 1),
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.AnnealingConfig) Config {
+	cfg := DefaultConfig()
+	if c.Iterations > 0 {
+		cfg.Iterations = c.Iterations
+	}
+	from, to := 5000.0, 1.0
+	if c.StartTemp > 0 {
+		from = c.StartTemp
+	}
+	if c.EndTemp > 0 {
+		to = c.EndTemp
+	}
+	switch c.Cooling {
+	case "linear":
+		cfg.Schedule = Linear(from, to)
+	default:
+		cfg.Schedule = Geometric(from, to)
+	}
+	cfg.Seed = c.Seed
+	return cfg
+}
+
+type annealing struct {
+	opt   optimizer.Optimizer
+	cfg   Config
+	moves []Move
+}
+
+// New - имитация отжига, стартующая с решения оптимизатора opt.
+// Без moves используются ReassignDriver, SwapTails и MoveBus.
+func New(opt optimizer.Optimizer, cfg Config, moves ...Move) optimizer.Optimizer {
+	if len(moves) == 0 {
+		moves = []Move{ReassignDriver{}, SwapTails{}, MoveBus{}}
+	}
+	return &annealing{opt: opt, cfg: cfg, moves: moves}
+}
+
+func (a *annealing) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	base, err := a.opt.Optimize(ctx, tt, buses, drvs)
+	if err != nil {
+		return base, err
+	}
+	iterations := base.Iterations
+
+	seed := a.cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rnd := rand.New(rand.NewPCG(seed, seed))
+
+	s := &localsearch.State{TT: tt, Buses: buses, Drivers: drvs}
+	energy := optimizer.Penalized(tt, buses, drvs)
+	bestEnergy := energy
+	best := optimizer.TakeSnapshot(tt, buses, drvs)
+
+	for step := 0; step < a.cfg.Iterations && ctx.Err() == nil; step++ {
+		iterations++
+
+		s.Begin()
+		if !a.moves[rnd.IntN(len(a.moves))].Apply(s, rnd) {
+			s.Rollback()
+			continue
+		}
+
+		next := optimizer.Penalized(tt, buses, drvs)
+		temp := a.cfg.Schedule(step, a.cfg.Iterations)
+		if next <= energy || rnd.Float64() < math.Exp((energy-next)/temp) {
+			s.Commit()
+			energy = next
+			if energy < bestEnergy {
+				bestEnergy = energy
+				best = optimizer.TakeSnapshot(tt, buses, drvs)
+			}
+		} else {
+			s.Rollback()
+		}
+		optimizer.Notify(ctx, optimizer.Event{
+			Optimizer: "annealing",
+			Epoch:     step + 1,
+			Best:      bestEnergy,
+			Current:   energy,
+			Drivers:   best.Drivers(),
+			Buses:     best.Buses(),
+			Elapsed:   time.Since(start),
+		})
+	}
+
+	best.Restore(tt, buses, drvs)
+	buses.AllocateDepots(tt)
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = iterations
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
+	}
+	return res, nil
+}
@@ -0,0 +1,120 @@
+package annealing
+
+import (
+	"course/optimizer/localsearch"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           Schedule
+		step, total int
+		want        float64
+	}{
+		{"geometric start", Geometric(1000, 1), 0, 10, 1000},
+		{"geometric middle", Geometric(1000, 10), 5, 10, 100},
+		{"geometric end", Geometric(1000, 1), 10, 10, 1},
+		{"linear start", Linear(100, 0), 0, 4, 100},
+		{"linear middle", Linear(100, 0), 1, 4, 75},
+		{"linear end", Linear(100, 0), 4, 4, 0},
+		{"no steps", Linear(100, 0), 0, 0, 100},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.s(tc.step, tc.total); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("temperature = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// assignment - водитель и автобус каждого пути, штат и парк
+type assignment struct {
+	paths   map[uuid.UUID][2]uuid.UUID
+	drivers map[uuid.UUID]bool
+	buses   map[uuid.UUID]bool
+}
+
+func assignmentOf(s *localsearch.State) assignment {
+	a := assignment{
+		paths:   make(map[uuid.UUID][2]uuid.UUID),
+		drivers: make(map[uuid.UUID]bool),
+		buses:   make(map[uuid.UUID]bool),
+	}
+	for id, p := range s.TT.GetEach(func(p path.Path) bool { return true }) {
+		a.paths[id] = [2]uuid.UUID{p.DriverID, p.BusID}
+	}
+	for id := range s.Drivers.Drivers() {
+		a.drivers[id] = true
+	}
+	for id := range s.Buses.Buses() {
+		a.buses[id] = true
+	}
+	return a
+}
+
+// откат хода возвращает назначения, штат и парк в точности к прежним
+func TestMovesRollback(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+
+	for _, m := range []Move{ReassignDriver{}, SwapTails{}, MoveBus{}} {
+		t.Run(m.Name(), func(t *testing.T) {
+			ttb := ttv1.NewBuilder()
+			var ps []path.Path
+			for i := 0; i < 8; i++ {
+				from, to := a, b
+				if i%2 == 1 {
+					from, to = b, a
+				}
+				start := day.Add(time.Duration(6+i) * time.Hour)
+				p := path.Path{
+					ID:        uuid.UUID{byte(i + 1)},
+					Number:    i + 1,
+					Points:    []path.Point{from, to},
+					StartTime: start,
+					EndTime:   start.Add(50 * time.Minute),
+				}
+				ps = append(ps, p)
+				ttb.AddPath(p, nil)
+			}
+			s := &localsearch.State{
+				TT:      ttb.Build(),
+				Buses:   station.NewBusStationBuilder().Build(),
+				Drivers: driverhub.NewDriverHubBuilder().Build(),
+			}
+			drvs := []driver.Driver{driver.New(uuid.UUID{0xd1}, "A"), driver.New(uuid.UUID{0xd2}, "B")}
+			buses := []*bus.Bus{bus.NewBus(uuid.UUID{0xb1}), bus.NewBus(uuid.UUID{0xb2})}
+			for i, p := range ps {
+				s.AssignDriver(p.ID, drvs[i/4])
+				s.AssignBus(p.ID, buses[i/4])
+			}
+
+			rnd := rand.New(rand.NewPCG(1, 2))
+			for i := 0; i < 100; i++ {
+				before := assignmentOf(s)
+				s.Begin()
+				m.Apply(s, rnd)
+				s.Rollback()
+				after := assignmentOf(s)
+				if !maps.Equal(after.paths, before.paths) || !maps.Equal(after.drivers, before.drivers) ||
+					!maps.Equal(after.buses, before.buses) {
+					t.Fatalf("step %d: rollback left %+v, want %+v", i, after, before)
+				}
+			}
+		})
+	}
+}
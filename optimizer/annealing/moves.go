@@ -0,0 +1,116 @@
+package annealing
+
+import (
+	"course/optimizer/localsearch"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"math/rand/v2"
+)
+
+// Move - ход в соседнее решение.
+// Apply меняет localsearch.State; ok == false, если ход в текущем решении
+// невозможен. Отжиг вызывает Apply в транзакции State и отменяет
+// отвергнутый ход через Rollback.
+type Move interface {
+	Name() string
+	Apply(s *localsearch.State, rnd *rand.Rand) (ok bool)
+}
+
+// ReassignDriver отдает случайный путь другому водителю из штата,
+// свободному на время пути, а если таких нет - новому водителю случайного типа
+type ReassignDriver struct{}
+
+func (ReassignDriver) Name() string { return "reassign-driver" }
+
+func (ReassignDriver) Apply(s *localsearch.State, rnd *rand.Rand) bool {
+	paths := s.PathIDs(func(p path.Path) bool { return true })
+	if len(paths) == 0 {
+		return false
+	}
+	p := s.TT.GetPathByID(paths[rnd.IntN(len(paths))])
+
+	var free []uuid.UUID
+	for _, id := range s.DriverIDs() {
+		if id != p.DriverID && s.DriverFree(id, p) {
+			free = append(free, id)
+		}
+	}
+
+	var d driver.Driver
+	if len(free) == 0 {
+		types := driver.Types()
+		d = driver.New(s.Drivers.NewID(), types[rnd.IntN(len(types))].Name)
+	} else {
+		d = s.Drivers.GetDriver(free[rnd.IntN(len(free))])
+	}
+	s.AssignDriver(p.ID, d)
+	return true
+}
+
+// SwapTails обменивает хвосты смен двух водителей:
+// все их пути, начинающиеся не раньше случайного момента
+type SwapTails struct{}
+
+func (SwapTails) Name() string { return "swap-tails" }
+
+func (SwapTails) Apply(s *localsearch.State, rnd *rand.Rand) bool {
+	drvs := s.DriverIDs()
+	if len(drvs) < 2 {
+		return false
+	}
+	i := rnd.IntN(len(drvs))
+	j := rnd.IntN(len(drvs) - 1)
+	if j >= i {
+		j++
+	}
+	d1, d2 := s.Drivers.GetDriver(drvs[i]), s.Drivers.GetDriver(drvs[j])
+
+	own := s.PathIDs(func(p path.Path) bool { return p.DriverID == d1.ID() })
+	if len(own) == 0 {
+		return false
+	}
+	from := s.TT.GetPathByID(own[rnd.IntN(len(own))]).StartTime
+
+	tail1 := s.PathIDs(func(p path.Path) bool { return p.DriverID == d1.ID() && !p.StartTime.Before(from) })
+	tail2 := s.PathIDs(func(p path.Path) bool { return p.DriverID == d2.ID() && !p.StartTime.Before(from) })
+
+	for _, id := range tail1 {
+		s.AssignDriver(id, d2)
+	}
+	for _, id := range tail2 {
+		s.AssignDriver(id, d1)
+	}
+	return true
+}
+
+// MoveBus переносит случайный путь на другой автобус парка, который свободен
+// на время пути и стоит в его начальной точке, а если таких нет - на новый автобус
+type MoveBus struct{}
+
+func (MoveBus) Name() string { return "move-bus" }
+
+func (MoveBus) Apply(s *localsearch.State, rnd *rand.Rand) bool {
+	paths := s.PathIDs(func(p path.Path) bool { return true })
+	if len(paths) == 0 {
+		return false
+	}
+	p := s.TT.GetPathByID(paths[rnd.IntN(len(paths))])
+
+	var free []uuid.UUID
+	for _, id := range s.BusIDs() {
+		if id != p.BusID && s.BusFree(id, p) {
+			free = append(free, id)
+		}
+	}
+
+	var b *bus.Bus
+	if len(free) == 0 {
+		b = bus.NewBus(s.Buses.NewID())
+	} else {
+		b = s.Buses.GetBus(free[rnd.IntN(len(free))])
+	}
+	s.AssignBus(p.ID, b)
+	return true
+}
@@ -0,0 +1,204 @@
+package bnb
+
+import (
+	"context"
+	"course/cost"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"math"
+	"slices"
+	"time"
+)
+
+// chain - открытый блок автобуса в узле дерева поиска: номера путей по времени начала
+type chain []int
+
+// blockSearch - ветвление по блокам автобусов. Стоимость блока - найм автобуса
+// и машинное время от выезда из депо до возврата, как в cost.Model.Score.
+type blockSearch struct {
+	ctx    context.Context
+	tt     timetable.Timetable
+	trips  []path.Path
+	depots []path.Point
+
+	busCost float64
+	vehicle float64
+	// suffix[i] - суммарная длительность путей i..n-1
+	suffix []time.Duration
+	// conc[i] - наибольшее число одновременных путей среди i..n-1
+	conc []int
+
+	rootBound float64
+	nodes     int
+	complete  bool
+
+	chains   []chain
+	best     []chain
+	bestCost float64
+}
+
+func newBlockSearch(ctx context.Context, tt timetable.Timetable, trips []path.Path, depots []path.Point) *blockSearch {
+	w := cost.M().Weights()
+	s := &blockSearch{
+		ctx:      ctx,
+		tt:       tt,
+		trips:    trips,
+		depots:   depots,
+		busCost:  w.Bus,
+		vehicle:  w.VehicleHour,
+		bestCost: math.Inf(1),
+	}
+	n := len(trips)
+	s.suffix = make([]time.Duration, n+1)
+	s.conc = make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		s.suffix[i] = s.suffix[i+1] + trips[i].EndTime.Sub(trips[i].StartTime)
+		s.conc[i] = maxConcurrency(trips[i:])
+	}
+	// автобусов не меньше, чем путей, идущих одновременно,
+	// и каждый путь занимает автобус все свое время
+	s.rootBound = float64(s.conc[0])*s.busCost + s.suffix[0].Hours()*s.vehicle
+	return s
+}
+
+// pullOut - кратчайший выезд из какого-нибудь депо к началу пути i.
+// Без депо автобус выходит прямо к пути.
+func (s *blockSearch) pullOut(i int) (time.Duration, bool) {
+	if len(s.depots) == 0 {
+		return 0, true
+	}
+	best, found := time.Duration(0), false
+	for _, d := range s.depots {
+		out, ok := s.tt.Deadhead(d.ID(), s.trips[i].Points[0].ID())
+		if ok && (!found || out < best) {
+			best, found = out, true
+		}
+	}
+	return best, found
+}
+
+// cost - стоимость блока с лучшим для него депо; бесконечность,
+// если ни одно депо не связано с обоими концами блока
+func (s *blockSearch) cost(c chain) float64 {
+	first, last := s.trips[c[0]], s.trips[c[len(c)-1]]
+	var move time.Duration
+	if len(s.depots) > 0 {
+		found := false
+		for _, d := range s.depots {
+			out, okOut := s.tt.Deadhead(d.ID(), first.Points[0].ID())
+			in, okIn := s.tt.Deadhead(last.Last().ID(), d.ID())
+			if okOut && okIn && (!found || out+in < move) {
+				move, found = out+in, true
+			}
+		}
+		if !found {
+			return math.Inf(1)
+		}
+	}
+	return s.busCost + (last.EndTime.Sub(first.StartTime)+move).Hours()*s.vehicle
+}
+
+func (s *blockSearch) total(chains []chain) float64 {
+	var sum float64
+	for _, c := range chains {
+		sum += s.cost(c)
+	}
+	return sum
+}
+
+// seed берет первым решением покрытие наименьшим числом автобусов из vsp
+func (s *blockSearch) seed(blocks [][]path.Path) {
+	index := make(map[uuid.UUID]int, len(s.trips))
+	for i, p := range s.trips {
+		index[p.ID] = i
+	}
+	chains := make([]chain, 0, len(blocks))
+	for _, blk := range blocks {
+		c := make(chain, 0, len(blk))
+		for _, p := range blk {
+			c = append(c, index[p.ID])
+		}
+		chains = append(chains, c)
+	}
+	if total := s.total(chains); total < s.bestCost {
+		s.bestCost = total
+		s.best = chains
+	}
+}
+
+func (s *blockSearch) run() {
+	if len(s.trips) == 0 {
+		s.complete = true
+		s.best = []chain{}
+		s.bestCost = 0
+		return
+	}
+	s.branch(0, 0)
+	s.complete = s.ctx.Err() == nil
+}
+
+// bound - нижняя граница стоимости любого завершения узла: каждый оставшийся
+// путь занимает автобус все свое время, а одновременным путям не хватит
+// открытых блоков
+func (s *blockSearch) bound(i int, partial float64) float64 {
+	extra := max(s.conc[i]-len(s.chains), 0)
+	return max(partial+s.suffix[i].Hours()*s.vehicle+float64(extra)*s.busCost, s.rootBound)
+}
+
+// branch ставит путь i в конец одного из открытых блоков или открывает новый.
+// partial - стоимость открытых блоков без возврата в депо.
+func (s *blockSearch) branch(i int, partial float64) {
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		return
+	}
+	if s.bound(i, partial) >= s.bestCost {
+		return
+	}
+	if i == len(s.trips) {
+		if total := s.total(s.chains); total < s.bestCost {
+			s.bestCost = total
+			s.best = slices.Clone(s.chains)
+		}
+		return
+	}
+	p := s.trips[i]
+
+	// сначала блоки, открытые позже всех: их автобусы обычно освобождаются позже
+	for k := len(s.chains) - 1; k >= 0; k-- {
+		old := s.chains[k]
+		prev := s.trips[old[len(old)-1]]
+		if !s.tt.Follows(prev, p) {
+			continue
+		}
+		s.chains[k] = append(slices.Clip(old), i)
+		s.branch(i+1, partial+p.EndTime.Sub(prev.EndTime).Hours()*s.vehicle)
+		s.chains[k] = old
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+
+	// новые блоки взаимозаменяемы, поэтому открываем один
+	out, ok := s.pullOut(i)
+	if !ok {
+		return
+	}
+	s.chains = append(s.chains, chain{i})
+	s.branch(i+1, partial+s.busCost+(p.EndTime.Sub(p.StartTime)+out).Hours()*s.vehicle)
+	s.chains = s.chains[:len(s.chains)-1]
+}
+
+// blocks - пути лучших блоков
+func (s *blockSearch) blocks() [][]path.Path {
+	blocks := make([][]path.Path, 0, len(s.best))
+	for _, c := range s.best {
+		blk := make([]path.Path, 0, len(c))
+		for _, i := range c {
+			blk = append(blk, s.trips[i])
+		}
+		blocks = append(blocks, blk)
+	}
+	return blocks
+}
@@ -0,0 +1,343 @@
+package bnb
+
+import (
+	"cmp"
+	"context"
+	"course/cost"
+	"course/optimizer"
+	"course/optimizer/vsp"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"math"
+	"slices"
+	"time"
+)
+
+type bnb struct{}
+
+// New - точный метод ветвей и границ для небольших расписаний.
+// Ограничения и стоимость водителей и автобусов независимы, поэтому перебор
+// идет в два этапа: сначала блоки автобусов с машинным временем, выездом
+// из лучшего для блока депо и возвратом в него, начиная с покрытия vsp,
+// затем смены водителей.
+// На блоки уходит половина оставшегося бюджета. Решение StatusOptimal, когда
+// оба перебора завершены и стоимость расписания совпала с нижней границей;
+// если допустимых смен нет, StatusInfeasible.
+// Если бюджет истек, возвращается лучшее найденное решение
+// с нижней границей, по которой считается разрыв.
+func New() optimizer.Optimizer {
+	return &bnb{}
+}
+
+func (b *bnb) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return optimizer.Result{Status: optimizer.StatusTimedOut}, err
+	}
+	start := time.Now()
+
+	var depots []path.Point
+	for _, d := range buses.Depots() {
+		if d.ID() != uuid.Nil {
+			depots = append(depots, d)
+		}
+	}
+	s := newSearch(ctx, tt)
+	bs := newBlockSearch(ctx, tt, s.trips, depots)
+	blocks := vsp.Blocks(tt)
+	bs.seed(blocks)
+	// блокам автобусов - половина оставшегося бюджета, остальное - сменам
+	bctx, cancel := ctx, context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		bctx, cancel = context.WithDeadline(ctx, time.Now().Add(time.Until(deadline)/2))
+	}
+	bs.ctx = bctx
+	bs.run()
+	cancel()
+	// без блоков, связанных с депо, остается покрытие vsp: нарушения покажет валидатор
+	busCost := bs.rootBound
+	if bs.best != nil {
+		blocks, busCost = bs.blocks(), bs.bestCost
+	}
+	vsp.AssignBuses(tt, buses, blocks)
+
+	s.start, s.buses, s.busCost = start, len(blocks), busCost
+	s.run()
+
+	if s.best != nil {
+		s.apply(tt, drvs)
+	}
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = bs.nodes + s.nodes
+	res.WallTime = time.Since(start)
+	// полный перебор дает точную стоимость блоков и смен
+	busBound := bs.rootBound
+	if bs.complete && bs.best != nil {
+		busBound = bs.bestCost
+	}
+	res.LowerBound = busBound + s.rootBound
+	if s.complete && s.best != nil {
+		res.LowerBound = busBound + s.bestCost
+	}
+	switch {
+	case ctx.Err() != nil:
+		res.Status = optimizer.StatusTimedOut
+	case s.best == nil:
+		// перебор завершен, но ни одной допустимой расстановки смен нет
+		res.Status = optimizer.StatusInfeasible
+	case math.Abs(res.Objective-res.LowerBound) <= 1e-6*max(1, math.Abs(res.Objective)):
+		res.Status = optimizer.StatusOptimal
+		res.LowerBound = res.Objective
+	}
+	return res, nil
+}
+
+// duty - открытая смена в узле дерева поиска
+type duty struct {
+	proto driver.Driver
+	// номер типа proto в search.protos
+	typ     int
+	paths   []path.Path
+	trips   []int
+	first   time.Time
+	lastEnd time.Time
+	driving time.Duration
+	// cost.M().Driver смены
+	cost float64
+}
+
+// with - смена с добавленным путем p; ok == false, если смена нарушит
+// правила типа водителя. Пути приходят по началу, поэтому пересечение
+// с последним путем отсекается сразу, остальное проверяют правила.
+func (d duty) with(i int, p path.Path) (duty, bool) {
+	if len(d.paths) > 0 && p.StartTime.Before(d.lastEnd) {
+		return d, false
+	}
+	paths := append(slices.Clip(d.paths), p)
+	if !d.proto.Spec().Legal(paths) {
+		return d, false
+	}
+	if len(d.paths) == 0 {
+		d.first = p.StartTime
+	}
+	d.paths = paths
+	d.trips = append(slices.Clip(d.trips), i)
+	d.lastEnd = p.EndTime
+	d.driving += p.EndTime.Sub(p.StartTime)
+	d.cost = cost.M().Driver(d.proto, paths).Total()
+	return d, true
+}
+
+// limits - сколько может длиться смена и сколько в ней можно вести
+// по правилам типа proto; без ограничения - сутки
+func limits(proto driver.Driver) (spread, driving time.Duration) {
+	spread, driving = 24*time.Hour, 24*time.Hour
+	for _, r := range proto.Spec().Rules {
+		switch r := r.(type) {
+		case driver.MaxSpread:
+			spread = min(spread, r.Limit)
+		case driver.EUDailyDrivingRule:
+			driving = min(driving, driver.EUExtendedDaily)
+		}
+	}
+	return spread, min(spread, driving)
+}
+
+type search struct {
+	ctx    context.Context
+	trips  []path.Path
+	protos []driver.Driver
+	// limits по типам protos
+	spreads, drivings []time.Duration
+
+	// минимальная стоимость часа вождения среди типов водителей
+	hourCost float64
+	// suffix[i] - суммарное вождение путей i..n-1
+	suffix []time.Duration
+
+	rootBound float64
+	nodes     int
+	complete  bool
+
+	// для событий наблюдателя: блоки автобусов уже выбраны и в поиске смен не меняются
+	start   time.Time
+	buses   int
+	busCost float64
+
+	duties   []duty
+	best     []duty
+	bestCost float64
+}
+
+func newSearch(ctx context.Context, tt timetable.Timetable) *search {
+	s := &search{
+		ctx:      ctx,
+		protos:   driver.Prototypes(),
+		hourCost: math.Inf(1),
+		bestCost: math.Inf(1),
+	}
+	s.trips = tt.PathsByStart()
+
+	// длинные смены первыми: так быстрее находится первое решение
+	slices.SortStableFunc(s.protos, func(a, b driver.Driver) int {
+		return cmp.Compare(b.WorkDur(), a.WorkDur())
+	})
+
+	minFixed := math.Inf(1)
+	for _, proto := range s.protos {
+		spread, driving := limits(proto)
+		s.spreads = append(s.spreads, spread)
+		s.drivings = append(s.drivings, driving)
+		f := cost.M().Driver(proto, nil).Drivers
+		minFixed = min(minFixed, f)
+		s.hourCost = min(s.hourCost, f/driving.Hours())
+	}
+
+	s.suffix = make([]time.Duration, len(s.trips)+1)
+	for i := len(s.trips) - 1; i >= 0; i-- {
+		s.suffix[i] = s.suffix[i+1] + s.trips[i].EndTime.Sub(s.trips[i].StartTime)
+	}
+
+	// водителей не меньше, чем путей, идущих одновременно,
+	// и не меньше, чем нужно, чтобы отработать все часы вождения
+	s.rootBound = max(
+		float64(maxConcurrency(s.trips))*minFixed,
+		s.suffix[0].Hours()*s.hourCost,
+	)
+	return s
+}
+
+func (s *search) run() {
+	if len(s.trips) == 0 {
+		s.complete = true
+		s.best = []duty{}
+		s.bestCost = 0
+		return
+	}
+	s.branch(0, 0)
+	s.complete = s.ctx.Err() == nil
+}
+
+// bound - нижняя граница стоимости любого завершения узла:
+// уже открытые смены плюс часы оставшихся путей, которые в них не поместятся
+func (s *search) bound(i int, partial float64) float64 {
+	var capacity time.Duration
+	for k := range s.duties {
+		d := &s.duties[k]
+		capacity += max(min(d.first.Add(s.spreads[d.typ]).Sub(d.lastEnd), s.drivings[d.typ]-d.driving), 0)
+	}
+	extra := max(s.suffix[i]-capacity, 0)
+	return max(partial+extra.Hours()*s.hourCost, s.rootBound)
+}
+
+func (s *search) branch(i int, partial float64) {
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		return
+	}
+	if s.bound(i, partial) >= s.bestCost {
+		return
+	}
+	if i == len(s.trips) {
+		s.bestCost = partial
+		s.best = make([]duty, len(s.duties))
+		copy(s.best, s.duties)
+		optimizer.Notify(s.ctx, optimizer.Event{
+			Optimizer: "bnb",
+			Epoch:     s.nodes,
+			Best:      s.busCost + s.bestCost,
+			Current:   s.busCost + s.bestCost,
+			Drivers:   len(s.best),
+			Buses:     s.buses,
+			Elapsed:   time.Since(s.start),
+		})
+		return
+	}
+	p := s.trips[i]
+
+	// сначала пробуем смены, освободившиеся позже всех: так быстрее находится
+	// хорошее первое решение
+	order := make([]int, 0, len(s.duties))
+	for k := range s.duties {
+		order = append(order, k)
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return s.duties[b].lastEnd.Compare(s.duties[a].lastEnd)
+	})
+
+	for _, k := range order {
+		old := s.duties[k]
+		next, ok := old.with(i, p)
+		if !ok {
+			continue
+		}
+		s.duties[k] = next
+		s.branch(i+1, partial-old.cost+next.cost)
+		s.duties[k] = old
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+
+	// новые смены одного типа взаимозаменяемы, поэтому открываем по одной каждого типа
+	for t, proto := range s.protos {
+		next, ok := duty{proto: proto, typ: t}.with(i, p)
+		if !ok {
+			continue
+		}
+		s.duties = append(s.duties, next)
+		s.branch(i+1, partial+next.cost)
+		s.duties = s.duties[:len(s.duties)-1]
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// apply нанимает по водителю на каждую смену лучшего решения, прежние водители увольняются
+func (s *search) apply(tt timetable.Timetable, drvs *driverhub.DriverHub) {
+	for id := range drvs.Drivers() {
+		drvs.Unregister(id)
+	}
+	for _, d := range s.best {
+		drv := driver.New(drvs.NewID(), d.proto.Type())
+		drvs.Register(drv)
+		for _, i := range d.trips {
+			tt.AssignDriverToPath(s.trips[i].ID, drv.ID())
+		}
+	}
+}
+
+// maxConcurrency - наибольшее число путей, идущих в один момент
+func maxConcurrency(trips []path.Path) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, len(trips)*2)
+	for _, p := range trips {
+		events = append(events, event{p.StartTime, 1}, event{p.EndTime, -1})
+	}
+	// окончание раньше начала в тот же момент: такие пути не пересекаются
+	slices.SortFunc(events, func(a, b event) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return a.delta - b.delta
+	})
+	cur, best := 0, 0
+	for _, e := range events {
+		cur += e.delta
+		best = max(best, cur)
+	}
+	return best
+}
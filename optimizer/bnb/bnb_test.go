@@ -0,0 +1,90 @@
+package bnb
+
+import (
+	"context"
+	"course/cost"
+	"course/optimizer"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"errors"
+	"github.com/google/uuid"
+	"math"
+	"testing"
+	"time"
+)
+
+// buildTimetable - пути туда и обратно между двумя конечными;
+// hours - начало и конец каждого пути в часах от полуночи
+func buildTimetable(hours ...[2]float64) *ttv1.TimeTable {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	at := func(h float64) time.Time { return day.Add(time.Duration(h * float64(time.Hour))) }
+	ttb := ttv1.NewBuilder()
+	for i, h := range hours {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: at(h[0]),
+			EndTime:   at(h[1]),
+		}, nil)
+	}
+	return ttb.Build()
+}
+
+func TestBnB(t *testing.T) {
+	w := cost.M().Weights()
+	// 6-8, 9-11, 11:30-12:30 ведет один водитель на одном автобусе, депо нет,
+	// и автобус занят 6,5 часа. У типа A перерыв один, час, и простой - полчаса;
+	// у типа B оба промежутка перерывы по 20 минут, и простой 50 минут.
+	a, _ := driver.Lookup("A")
+	b, _ := driver.Lookup("B")
+	optimum := w.Bus + 6.5*w.VehicleHour + min(a.ShiftCost()+0.5*w.IdleHour, b.ShiftCost()+50.0/60*w.IdleHour)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		hours  [][2]float64
+		status optimizer.Status
+		// ожидаемая стоимость; 0 - не проверяется
+		objective float64
+		err       error
+	}{
+		{"known optimum", context.Background(), [][2]float64{{6, 8}, {9, 11}, {11.5, 12.5}},
+			optimizer.StatusOptimal, optimum, nil},
+		{"empty", context.Background(), nil, optimizer.StatusOptimal, 0, nil},
+		// пять часов без перерыва не может ехать ни один тип водителя
+		{"no legal duty", context.Background(), [][2]float64{{6, 11}}, optimizer.StatusInfeasible, 0, nil},
+		{"budget spent before the start", canceled, [][2]float64{{6, 8}},
+			optimizer.StatusTimedOut, 0, context.Canceled},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := New().Optimize(tc.ctx, buildTimetable(tc.hours...),
+				station.NewBusStationBuilder().Build(), driverhub.NewDriverHubBuilder().Build())
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if res.Status != tc.status {
+				t.Errorf("Status = %s, want %s", res.Status, tc.status)
+			}
+			if tc.objective != 0 && math.Abs(res.Objective-tc.objective) > 1e-9 {
+				t.Errorf("Objective = %v, want %v", res.Objective, tc.objective)
+			}
+			if res.Status == optimizer.StatusOptimal && res.LowerBound != res.Objective {
+				t.Errorf("LowerBound = %v, want Objective %v", res.LowerBound, res.Objective)
+			}
+		})
+	}
+}
@@ -7,37 +7,42 @@ import (
 	"course/pkg/driverhub"
 	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable"
 	"github.com/google/uuid"
 	"log/slog"
 	"math/rand/v2"
 	"slices"
 )
 
-type bruteForce struct{}
+type bruteForce struct {
+	rnd *rand.Rand
+}
 
-func NewBrutForceOptimizer() optimizer.Optimizer {
-	return &bruteForce{}
+// NewBrutForceOptimizer - перебор путей по порядку; тип нанимаемого водителя
+// выбирается случайно по зерну seed, 0 - случайное зерно
+func NewBrutForceOptimizer(seed uint64) optimizer.Optimizer {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return optimizer.Adapt(&bruteForce{rnd: rand.New(rand.NewPCG(seed, seed))})
 }
 
 func (bf *bruteForce) Optimize(
-	tt *ttv1.TimeTable,
+	tt timetable.Timetable,
 	buses *station.BusStation,
 	drvs *driverhub.DriverHub,
 ) {
-	for _, p := range tt.Paths() {
+	for _, id := range tt.PathIDs() {
+		p := tt.GetPathByID(id)
 		if p.DriverID == uuid.Nil {
-			drv := drvs.GetNotInWork(tt, p.StartTime)
+			drv := drvs.GetNotInWork(tt, p)
 			if drv == nil {
-				if rand.Int()%2 == 0 {
-					drv = driver.NewDriverA()
-				} else {
-					drv = driver.NewDriverB()
-				}
+				types := driver.Types()
+				drv = driver.New(drvs.NewID(), types[bf.rnd.IntN(len(types))].Name)
 				slog.Info(
 					"brute-force-optimizer",
 					slog.String("driverID", drv.ID().String()),
-					slog.Int("type", drv.Type()),
+					slog.String("type", drv.Type()),
 					slog.String("status", "hired"),
 				)
 				drvs.Register(drv)
@@ -60,9 +65,9 @@ func (bf *bruteForce) Optimize(
 		}
 
 		if p.BusID == uuid.Nil {
-			bus := buses.GetNotInWork(tt, p.StartTime)
+			bus := buses.GetNotInWork(tt, p)
 			if bus == nil {
-				bus = bus2.NewBus(uuid.New())
+				bus = bus2.NewBus(buses.NewID())
 				buses.Register(bus)
 			}
 			tt.AssignBusToPath(p.ID, bus.ID)
@@ -0,0 +1,218 @@
+package crew
+
+import (
+	"context"
+	"course/config"
+	"course/cost"
+	"course/optimizer"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"math"
+	"slices"
+	"time"
+)
+
+// Config - ограничения перебора смен
+type Config struct {
+	// сколько ближайших по времени следующих путей пробуется при продлении смены
+	MaxSuccessors int
+	// сколько смен порождается, начиная с одного пути, для каждого типа водителя
+	MaxDutiesPerTrip int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxSuccessors:    3,
+		MaxDutiesPerTrip: 100,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.CrewConfig) Config {
+	cfg := DefaultConfig()
+	if c.MaxSuccessors > 0 {
+		cfg.MaxSuccessors = c.MaxSuccessors
+	}
+	if c.MaxDutiesPerTrip > 0 {
+		cfg.MaxDutiesPerTrip = c.MaxDutiesPerTrip
+	}
+	return cfg
+}
+
+type crew struct {
+	opt optimizer.Optimizer
+	cfg Config
+}
+
+// New - планирование смен водителей генерацией смен и покрытием множеств.
+// Автобусы назначает оптимизатор opt, назначения водителей которого
+// затем заменяются: перебираются допустимые смены каждого типа водителя,
+// и из них жадно, как в алгоритме Хватала, набирается дешевое покрытие всех путей.
+// Если покрыть все пути не удалось, водители opt остаются.
+func New(opt optimizer.Optimizer, cfg Config) optimizer.Optimizer {
+	return &crew{opt: opt, cfg: cfg}
+}
+
+// column - допустимая смена водителя типа typ из путей trips
+type column struct {
+	trips []int
+	typ   driver.DriverType
+	cost  float64
+}
+
+func (c *crew) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	base, err := c.opt.Optimize(ctx, tt, buses, drvs)
+	if err != nil {
+		return base, err
+	}
+
+	trips := tt.PathsByStart()
+
+	// без полного покрытия остаются назначения водителей оптимизатора opt
+	chosen, rounds, full := c.cover(ctx, trips)
+	if full {
+		apply(tt, drvs, trips, chosen)
+	}
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = base.Iterations + rounds
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
+	}
+	return res, nil
+}
+
+// cover - жадное покрытие множеств с порождением столбцов. Смены перебираются
+// по непокрытым путям, и на каждом шаге из смен, не задевающих покрытые пути,
+// берется смена с наименьшей стоимостью на один путь. Заново смены
+// перебираются, только когда таких осталось меньше половины перебранных:
+// так находятся цепочки в обход уже покрытых путей, а перебор идет
+// не на каждом шаге. full == false, если покрыть все пути не удалось,
+// например перебор прервал ctx.
+func (c *crew) cover(ctx context.Context, trips []path.Path) (chosen []column, rounds int, full bool) {
+	protos := driver.Prototypes()
+	covered := make([]bool, len(trips))
+	left := len(trips)
+
+	var pool []column
+	generated := 0
+	for left > 0 && ctx.Err() == nil {
+		rounds++
+		// смены, задевающие покрытые пути, больше не нужны
+		pool = slices.DeleteFunc(pool, func(col column) bool {
+			return slices.ContainsFunc(col.trips, func(t int) bool { return covered[t] })
+		})
+		if len(pool) <= generated/2 {
+			pool = pool[:0]
+			for _, proto := range protos {
+				pool = append(pool, c.enumerate(ctx, trips, covered, proto)...)
+			}
+			generated = len(pool)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		best, bestRatio := -1, math.Inf(1)
+		for i, col := range pool {
+			if r := col.cost / float64(len(col.trips)); r < bestRatio {
+				best, bestRatio = i, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+		chosen = append(chosen, pool[best])
+		for _, t := range pool[best].trips {
+			covered[t] = true
+			left--
+		}
+	}
+	return chosen, rounds, left == 0
+}
+
+// enumerate перебирает в глубину допустимые смены водителя типа proto
+// из непокрытых путей. Продление пробует MaxSuccessors ближайших путей,
+// начинающихся после конца последнего.
+func (c *crew) enumerate(
+	ctx context.Context,
+	trips []path.Path,
+	covered []bool,
+	proto driver.Driver,
+) []column {
+	var columns []column
+
+	for first := range trips {
+		if ctx.Err() != nil {
+			return columns
+		}
+		if covered[first] {
+			continue
+		}
+
+		count := 0
+		var dfs func(chain []int, ps []path.Path)
+		dfs = func(chain []int, ps []path.Path) {
+			if count >= c.cfg.MaxDutiesPerTrip {
+				return
+			}
+			count++
+			columns = append(columns, column{
+				trips: slices.Clone(chain),
+				typ:   proto.Type(),
+				cost:  cost.M().Driver(proto, ps).Total(),
+			})
+
+			last := trips[chain[len(chain)-1]]
+			successors := 0
+			for j := chain[len(chain)-1] + 1; j < len(trips) && successors < c.cfg.MaxSuccessors; j++ {
+				if covered[j] || trips[j].StartTime.Before(last.EndTime) {
+					continue
+				}
+				// ограниченная емкость: у каждого продления свой срез
+				next := append(ps[:len(ps):len(ps)], trips[j])
+				if !driver.NewShift(proto, next).Legal() {
+					continue
+				}
+				successors++
+				dfs(append(chain, j), next)
+			}
+		}
+
+		if driver.NewShift(proto, trips[first:first+1]).Legal() {
+			dfs([]int{first}, []path.Path{trips[first]})
+		}
+	}
+	return columns
+}
+
+// apply нанимает по водителю на каждую выбранную смену, прежние водители увольняются
+func apply(
+	tt timetable.Timetable,
+	drvs *driverhub.DriverHub,
+	trips []path.Path,
+	chosen []column,
+) {
+	for id := range drvs.Drivers() {
+		drvs.Unregister(id)
+	}
+
+	for _, col := range chosen {
+		d := driver.New(drvs.NewID(), col.typ)
+		drvs.Register(d)
+		for _, t := range col.trips {
+			tt.AssignDriverToPath(trips[t].ID, d.ID())
+		}
+	}
+}
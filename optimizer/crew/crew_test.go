@@ -0,0 +1,98 @@
+package crew
+
+import (
+	"context"
+	"course/optimizer"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"course/validate"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// perTrip - базовое решение: каждый путь ведет свой водитель типа A,
+// все пути идут на одном автобусе
+type perTrip struct{}
+
+func (perTrip) Optimize(
+	_ context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	b := bus.NewBus(uuid.UUID{0xb1})
+	buses.Register(b)
+	for id := range tt.GetEach(func(p path.Path) bool { return true }) {
+		d := driver.New(drvs.NewID(), "A")
+		drvs.Register(d)
+		tt.AssignDriverToPath(id, d.ID())
+		tt.AssignBusToPath(id, b.ID)
+	}
+	return optimizer.Summarize(tt, buses, drvs), nil
+}
+
+// buildTimetable - четыре часовых пути через полчаса друг за другом
+func buildTimetable() *ttv1.TimeTable {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 4; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(6*time.Hour + time.Duration(i)*90*time.Minute)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+		}, nil)
+	}
+	return ttb.Build()
+}
+
+func TestCrew(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		drivers int
+		status  optimizer.Status
+	}{
+		// все пути помещаются в одну смену
+		{"one duty", context.Background(), 1, optimizer.StatusFeasible},
+		// без полного покрытия остаются водители базового решения
+		{"canceled", canceled, 4, optimizer.StatusTimedOut},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := buildTimetable()
+			buses := station.NewBusStationBuilder().Build()
+			drvs := driverhub.NewDriverHubBuilder().Build()
+			res, err := New(perTrip{}, DefaultConfig()).Optimize(tc.ctx, tt, buses, drvs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Drivers != tc.drivers {
+				t.Errorf("Drivers = %d, want %d", res.Drivers, tc.drivers)
+			}
+			if res.Status != tc.status {
+				t.Errorf("Status = %s, want %s", res.Status, tc.status)
+			}
+			if vs := validate.Validate(tt, drvs, buses); len(vs) > 0 {
+				t.Errorf("Validate = %v", vs)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package gen_algorithm
+
+import (
+	"course/cost"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// problem - неизменные для всего запуска входные данные
+type problem struct {
+	tt timetable.Timetable
+	// пути в порядке начала, индекс пути - номер гена
+	trips []path.Path
+	// водители и автобусы, уже имеющиеся в штате: их слоты идут первыми
+	drivers []driver.Driver
+	buses   []uuid.UUID
+	// депо автобусов штата по слотам и депо станции для новых автобусов
+	depots []uuid.UUID
+	depot  uuid.UUID
+	// образцы водителей каждого типа для слотов новых водителей
+	protos map[driver.DriverType]driver.Driver
+	types  []driver.DriverType
+	w      cost.Weights
+}
+
+func newProblem(
+	tt timetable.Timetable,
+	bs *station.BusStation,
+	dh *driverhub.DriverHub,
+) *problem {
+	pr := &problem{
+		tt:     tt,
+		protos: make(map[driver.DriverType]driver.Driver),
+		w:      cost.M().Weights(),
+	}
+	for _, d := range driver.Prototypes() {
+		pr.protos[d.Type()] = d
+		pr.types = append(pr.types, d.Type())
+	}
+
+	pr.trips = tt.PathsByStart()
+
+	for _, d := range dh.Drivers() {
+		pr.drivers = append(pr.drivers, d)
+	}
+	slices.SortFunc(pr.drivers, func(a, b driver.Driver) int {
+		return strings.Compare(a.ID().String(), b.ID().String())
+	})
+
+	for id := range bs.Buses() {
+		pr.buses = append(pr.buses, id)
+	}
+	slices.SortFunc(pr.buses, func(a, b uuid.UUID) int {
+		return slices.Compare(a[:], b[:])
+	})
+	pr.depot = bs.Depot().Id
+	for _, id := range pr.buses {
+		pr.depots = append(pr.depots, bs.GetBus(id).Depot)
+	}
+
+	return pr
+}
+
+// chromosome - полное назначение: ген i - водитель и автобус пути trips[i].
+// Слоты водителей [0, len(problem.drivers)) - водители из штата,
+// дальше идут новые водители с типами из hired.
+// Слоты автобусов [0, len(problem.buses)) - автобусы из парка, дальше новые.
+type chromosome struct {
+	drv   []int
+	bus   []int
+	hired []driver.DriverType
+	nbus  int
+
+	fitness float64
+}
+
+func (c *chromosome) clone() *chromosome {
+	return &chromosome{
+		drv:     slices.Clone(c.drv),
+		bus:     slices.Clone(c.bus),
+		hired:   slices.Clone(c.hired),
+		nbus:    c.nbus,
+		fitness: c.fitness,
+	}
+}
+
+func (c *chromosome) driverSlots(pr *problem) int { return len(pr.drivers) + len(c.hired) }
+
+// hire добавляет слот нового водителя и возвращает его номер
+func (c *chromosome) hire(pr *problem, typ driver.DriverType) int {
+	c.hired = append(c.hired, typ)
+	return c.driverSlots(pr) - 1
+}
+
+func (pr *problem) driverOf(c *chromosome, slot int) driver.Driver {
+	if slot < len(pr.drivers) {
+		return pr.drivers[slot]
+	}
+	return pr.protos[c.hired[slot-len(pr.drivers)]]
+}
+
+// duties группирует пути по слотам водителей, сохраняя порядок начала
+func (pr *problem) duties(c *chromosome) [][]int {
+	res := make([][]int, c.driverSlots(pr))
+	for i, s := range c.drv {
+		res[s] = append(res[s], i)
+	}
+	return res
+}
+
+// used - сколько водителей и автобусов занято путями
+func (c *chromosome) used(pr *problem) (drivers, buses int) {
+	for _, duty := range pr.duties(c) {
+		if len(duty) > 0 {
+			drivers++
+		}
+	}
+	busy := make(map[int]bool)
+	for _, b := range c.bus {
+		busy[b] = true
+	}
+	return drivers, len(busy)
+}
+
+// normalize убирает пустые слоты новых водителей и автобусов
+func (c *chromosome) normalize(pr *problem) {
+	used := make([]bool, c.driverSlots(pr))
+	for _, s := range c.drv {
+		used[s] = true
+	}
+	remap := make([]int, len(used))
+	hired := make([]driver.DriverType, 0, len(c.hired))
+	for s := range used {
+		switch {
+		case s < len(pr.drivers):
+			remap[s] = s
+		case used[s]:
+			hired = append(hired, c.hired[s-len(pr.drivers)])
+			remap[s] = len(pr.drivers) + len(hired) - 1
+		}
+	}
+	for i, s := range c.drv {
+		c.drv[i] = remap[s]
+	}
+	c.hired = hired
+
+	usedBus := make([]bool, c.nbus)
+	for _, b := range c.bus {
+		usedBus[b] = true
+	}
+	remap = make([]int, c.nbus)
+	nbus := len(pr.buses)
+	for b := range usedBus {
+		switch {
+		case b < len(pr.buses):
+			remap[b] = b
+		case usedBus[b]:
+			remap[b] = nbus
+			nbus++
+		}
+	}
+	for i, b := range c.bus {
+		c.bus[i] = remap[b]
+	}
+	c.nbus = nbus
+}
+
+// evaluate считает приспособленность: стоимость по общей модели cost
+// плюс штраф за каждое нарушение жестких ограничений.
+// Путь, назначенный с нарушением, считается непокрытым.
+func (pr *problem) evaluate(c *chromosome) {
+	var (
+		fitness    float64
+		violations int
+	)
+
+	for s, duty := range pr.duties(c) {
+		if len(duty) == 0 {
+			continue
+		}
+		d := pr.driverOf(c, s)
+		ps := make([]path.Path, 0, len(duty))
+		for _, i := range duty {
+			ps = append(ps, pr.trips[i])
+		}
+		fitness += cost.M().Driver(d, ps).Total()
+
+		// по нарушению на каждое правило типа водителя
+		violations += len(driver.NewShift(d, ps).Violations())
+	}
+
+	first, last := make([]int, c.nbus), make([]int, c.nbus)
+	for b := range last {
+		first[b], last[b] = -1, -1
+	}
+	for i, b := range c.bus {
+		if last[b] >= 0 && !pr.busFollows(pr.trips[last[b]], pr.trips[i]) {
+			violations++
+		}
+		if first[b] < 0 {
+			first[b] = i
+		}
+		last[b] = i
+	}
+	for b, l := range last {
+		if l < 0 {
+			continue
+		}
+		fitness += pr.w.Bus
+		hours, ok := pr.blockHours(b, pr.trips[first[b]], pr.trips[l])
+		if !ok {
+			violations++
+		}
+		fitness += hours * pr.w.VehicleHour
+	}
+
+	c.fitness = fitness + float64(violations)*pr.w.Uncovered
+}
+
+// blockHours - машинные часы автобуса слота b, который выезжает из депо
+// к пути first и возвращается после пути last; ok == false, если депо
+// не связано с концами блока
+func (pr *problem) blockHours(b int, first, last path.Path) (float64, bool) {
+	depot := pr.depot
+	if b < len(pr.depots) {
+		depot = pr.depots[b]
+	}
+	if depot == uuid.Nil {
+		return last.EndTime.Sub(first.StartTime).Hours(), true
+	}
+	out, okOut := pr.tt.Deadhead(depot, first.Points[0].ID())
+	in, okIn := pr.tt.Deadhead(last.Points[len(last.Points)-1].ID(), depot)
+	return (last.EndTime.Sub(first.StartTime) + out + in).Hours(), okOut && okIn
+}
+
+// busFollows - может ли автобус после пути prev выполнить путь next,
+// с учетом холостого перегона
+func (pr *problem) busFollows(prev, next path.Path) bool {
+	return pr.tt.Follows(prev, next)
+}
+
+// duty - пути смены при пошаговом построении, по началу
+type duty []path.Path
+
+// canAppend - можно ли добавить путь в конец смены водителя d,
+// не нарушив правил его типа
+func (dt duty) canAppend(d driver.Driver, p path.Path) bool {
+	if len(dt) > 0 && p.StartTime.Before(dt[len(dt)-1].EndTime) {
+		return false
+	}
+	return d.Spec().Legal(append(slices.Clip(dt), p))
+}
+
+// fromTimeTable переносит в особь решение, уже записанное в расписание
+func (pr *problem) fromTimeTable() *chromosome {
+	c := &chromosome{
+		drv:  make([]int, len(pr.trips)),
+		bus:  make([]int, len(pr.trips)),
+		nbus: len(pr.buses),
+	}
+
+	drvSlot := make(map[uuid.UUID]int, len(pr.drivers))
+	for s, d := range pr.drivers {
+		drvSlot[d.ID()] = s
+	}
+	busSlot := make(map[uuid.UUID]int, len(pr.buses))
+	for s, id := range pr.buses {
+		busSlot[id] = s
+	}
+
+	for i, p := range pr.trips {
+		s, ok := drvSlot[p.DriverID]
+		if !ok {
+			s = c.hire(pr, pr.types[0])
+		}
+		c.drv[i] = s
+
+		b, ok := busSlot[p.BusID]
+		if !ok {
+			b = c.nbus
+			c.nbus++
+		}
+		c.bus[i] = b
+	}
+
+	pr.repairBuses(c)
+	c.normalize(pr)
+	pr.evaluate(c)
+	return c
+}
+
+// randomChromosome строит случайное допустимое назначение:
+// путь достается случайному водителю, который может его взять,
+// а если таких нет - новому водителю случайного типа
+func (pr *problem) randomChromosome(rnd *rand.Rand) *chromosome {
+	c := &chromosome{
+		drv:  make([]int, len(pr.trips)),
+		bus:  make([]int, len(pr.trips)),
+		nbus: len(pr.buses),
+	}
+
+	duties := make([]duty, len(pr.drivers))
+
+	var candidates []int
+	for i, p := range pr.trips {
+		candidates = candidates[:0]
+		for s, dt := range duties {
+			if dt.canAppend(pr.driverOf(c, s), p) {
+				candidates = append(candidates, s)
+			}
+		}
+
+		var s int
+		if len(candidates) > 0 {
+			s = candidates[rnd.IntN(len(candidates))]
+		} else {
+			s = c.hire(pr, pr.types[rnd.IntN(len(pr.types))])
+			duties = append(duties, nil)
+		}
+		duties[s] = append(duties[s], p)
+		c.drv[i] = s
+
+		c.bus[i] = rnd.IntN(max(c.nbus, 1))
+	}
+	c.nbus = max(c.nbus, 1)
+
+	pr.repairBuses(c)
+	c.normalize(pr)
+	pr.evaluate(c)
+	return c
+}
+
+// repairBuses переназначает пути, которые автобус не может выполнить,
+// на подходящий автобус особи, а если такого нет - на новый
+func (pr *problem) repairBuses(c *chromosome) {
+	last := make([]int, c.nbus)
+	for b := range last {
+		last[b] = -1
+	}
+
+	for i, b := range c.bus {
+		if last[b] >= 0 && !pr.busFollows(pr.trips[last[b]], pr.trips[i]) {
+			b = pr.freeBus(last, i)
+			if b < 0 {
+				b = c.nbus
+				c.nbus++
+				last = append(last, -1)
+			}
+			c.bus[i] = b
+		}
+		last[b] = i
+	}
+}
+
+// freeBus ищет автобус для пути i: сначала тот, что освободился позже всех
+// в нужной точке, затем еще не выходивший на линию. -1, если таких нет.
+func (pr *problem) freeBus(last []int, i int) int {
+	best, empty := -1, -1
+	for b, l := range last {
+		switch {
+		case l < 0:
+			if empty < 0 {
+				empty = b
+			}
+		case pr.busFollows(pr.trips[l], pr.trips[i]):
+			if best < 0 || pr.trips[l].EndTime.After(pr.trips[last[best]].EndTime) {
+				best = b
+			}
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	return empty
+}
+
+// apply записывает особь в расписание: нанимает новых водителей и автобусы,
+// а водителей и автобусы, оставшиеся без путей, выводит из штата
+func (pr *problem) apply(
+	c *chromosome,
+	tt timetable.Timetable,
+	bs *station.BusStation,
+	dh *driverhub.DriverHub,
+) {
+	drvIDs := make([]uuid.UUID, c.driverSlots(pr))
+	for s := range drvIDs {
+		if s < len(pr.drivers) {
+			drvIDs[s] = pr.drivers[s].ID()
+			continue
+		}
+		d := driver.New(dh.NewID(), c.hired[s-len(pr.drivers)])
+		dh.Register(d)
+		drvIDs[s] = d.ID()
+	}
+
+	busIDs := make([]uuid.UUID, c.nbus)
+	for b := range busIDs {
+		if b < len(pr.buses) {
+			busIDs[b] = pr.buses[b]
+			continue
+		}
+		nb := bus.NewBus(bs.NewID())
+		bs.Register(nb)
+		busIDs[b] = nb.ID
+	}
+
+	usedDrv := make(map[uuid.UUID]struct{})
+	usedBus := make(map[uuid.UUID]struct{})
+	for i, p := range pr.trips {
+		tt.AssignDriverToPath(p.ID, drvIDs[c.drv[i]])
+		tt.AssignBusToPath(p.ID, busIDs[c.bus[i]])
+		usedDrv[drvIDs[c.drv[i]]] = struct{}{}
+		usedBus[busIDs[c.bus[i]]] = struct{}{}
+	}
+
+	for _, d := range pr.drivers {
+		if _, ok := usedDrv[d.ID()]; !ok {
+			dh.Unregister(d.ID())
+		}
+	}
+	for _, id := range pr.buses {
+		if _, ok := usedBus[id]; !ok {
+			bs.Unregister(id)
+		}
+	}
+}
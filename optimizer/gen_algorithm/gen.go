@@ -1,147 +1,220 @@
 package gen_algorithm
 
 import (
+	"context"
+	"course/config"
 	"course/optimizer"
-	"course/pkg/bus"
 	"course/pkg/driverhub"
-	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
-	"github.com/google/uuid"
+	"course/pkg/timetable"
+	"math/rand/v2"
 	"slices"
 	"time"
 )
 
+// Config - параметры генетического алгоритма
+type Config struct {
+	Population     int
+	Generations    int
+	TournamentSize int
+	// сколько лучших особей переходит в следующее поколение без изменений
+	Elite         int
+	CrossoverRate float64
+	SwapRate      float64
+	RelocateRate  float64
+	// 0 - случайное зерно
+	Seed uint64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Population:     40,
+		Generations:    200,
+		TournamentSize: 3,
+		Elite:          2,
+		CrossoverRate:  0.9,
+		SwapRate:       0.2,
+		RelocateRate:   0.3,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.GeneticConfig) Config {
+	cfg := DefaultConfig()
+	if c.Population > 0 {
+		cfg.Population = c.Population
+	}
+	if c.Generations > 0 {
+		cfg.Generations = c.Generations
+	}
+	if c.TournamentSize > 0 {
+		cfg.TournamentSize = c.TournamentSize
+	}
+	if c.Elite > 0 {
+		cfg.Elite = c.Elite
+	}
+	if c.CrossoverRate > 0 {
+		cfg.CrossoverRate = c.CrossoverRate
+	}
+	if c.SwapRate > 0 {
+		cfg.SwapRate = c.SwapRate
+	}
+	if c.RelocateRate > 0 {
+		cfg.RelocateRate = c.RelocateRate
+	}
+	cfg.Seed = c.Seed
+	return cfg
+}
+
 type ga struct {
 	opt optimizer.Optimizer
+	cfg Config
+	rnd *rand.Rand
 }
 
-func New(opt optimizer.Optimizer) optimizer.Optimizer {
-	return &ga{opt: opt}
+// New - генетический алгоритм над полными назначениями путь→(водитель, автобус).
+// Решение базового оптимизатора opt становится одной из особей начальной популяции.
+func New(opt optimizer.Optimizer, cfg Config) optimizer.Optimizer {
+	return &ga{opt: opt, cfg: cfg}
 }
 
 func (g *ga) Optimize(
-	tt *ttv1.TimeTable,
+	ctx context.Context,
+	tt timetable.Timetable,
 	buses *station.BusStation,
 	drvs *driverhub.DriverHub,
-) {
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	seed := g.cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	g.rnd = rand.New(rand.NewPCG(seed, seed))
+
 	// используем оптимизатор, чтобы построить первичные пути
-	g.opt.Optimize(tt, buses, drvs)
+	base, err := g.opt.Optimize(ctx, tt, buses, drvs)
+	if err != nil {
+		return base, err
+	}
+	iterations := base.Iterations
+
+	pr := newProblem(tt, buses, drvs)
+	if len(pr.trips) > 0 {
+		population := g.initPopulation(pr)
+
+		epoch := epochCounter(g.cfg.Generations)
+		_, next := epoch()
+		for n := 1; next && ctx.Err() == nil; n++ {
+			_, next = epoch()
+			population = g.do(pr, population)
+			iterations++
+			g.notify(ctx, pr, population, n, start)
+		}
+
+		pr.apply(population[0], tt, buses, drvs)
+	}
+	buses.AllocateDepots(tt)
 
-	// 100 эпох
-	epoch := epochCounter(100)
-	_, next := epoch()
-	for next {
-		_, next = epoch()
-		g.do(tt, buses, drvs)
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = iterations
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
 	}
+	return res, nil
 }
 
-func (g *ga) do(
-	tt *ttv1.TimeTable,
-	buses *station.BusStation,
-	drvs *driverhub.DriverHub,
-) {
-	parents := g.selectForCrossover(buses, drvs, tt, 10)
-	index := 0
-	for index+1 < len(parents) {
-		g.crossover(tt, parents[index], parents[index+1])
-		index += 2
+// notify сообщает наблюдателю о лучшей и средней приспособленности эпохи
+func (g *ga) notify(ctx context.Context, pr *problem, population []*chromosome, epoch int, start time.Time) {
+	if optimizer.ObserverFrom(ctx) == nil {
+		return
+	}
+	var mean float64
+	for _, c := range population {
+		mean += c.fitness
 	}
+	mean /= float64(len(population))
+	drivers, buses := population[0].used(pr)
+	optimizer.Notify(ctx, optimizer.Event{
+		Optimizer: "gen_algorithm",
+		Epoch:     epoch,
+		Best:      population[0].fitness,
+		Current:   mean,
+		Drivers:   drivers,
+		Buses:     buses,
+		Elapsed:   time.Since(start),
+	})
 }
 
-func (g *ga) crossover(
-	tt *ttv1.TimeTable,
-	p1, p2 bus.Bus,
-) {
-	p1Paths := tt.GetEach(func(p path.Path) bool { return p.BusID == p1.ID })
-	p2Paths := tt.GetEach(func(p path.Path) bool { return p.BusID == p2.ID })
-
-	genom := len(p1Paths) + len(p2Paths)
-
-	counter := min(genom, len(p1Paths))
-	for _, v := range p1Paths {
-		tt.AssignBusToPath(v.ID, p1.ID)
-		counter--
-		if counter == 0 {
-			break
-		}
+// initPopulation строит популяцию из решения базового оптимизатора
+// и случайных допустимых назначений
+func (g *ga) initPopulation(pr *problem) []*chromosome {
+	population := make([]*chromosome, 0, g.cfg.Population)
+	population = append(population, pr.fromTimeTable())
+	for len(population) < g.cfg.Population {
+		population = append(population, pr.randomChromosome(g.rnd))
 	}
+	sortByFitness(population)
+	return population
+}
 
-	counter = min(genom, len(p2Paths))
+// do - одна эпоха: элита переходит без изменений,
+// остальные особи рождаются от родителей, выбранных турниром
+func (g *ga) do(pr *problem, population []*chromosome) []*chromosome {
+	next := make([]*chromosome, 0, len(population))
+	next = append(next, population[:min(g.cfg.Elite, len(population))]...)
+
+	for len(next) < len(population) {
+		p1 := g.tournament(population)
+		p2 := g.tournament(population)
+
+		var child *chromosome
+		if g.rnd.Float64() < g.cfg.CrossoverRate {
+			child = pr.crossover(g.rnd, p1, p2)
+		} else {
+			child = p1.clone()
+		}
 
-	for _, v := range p2Paths {
-		tt.AssignBusToPath(v.ID, p2.ID)
-		counter--
-		if counter == 0 {
-			break
+		if g.rnd.Float64() < g.cfg.SwapRate {
+			pr.swap(g.rnd, child)
 		}
+		if g.rnd.Float64() < g.cfg.RelocateRate {
+			pr.relocate(g.rnd, child)
+		}
+
+		pr.repairBuses(child)
+		child.normalize(pr)
+		pr.evaluate(child)
+		next = append(next, child)
 	}
 
+	sortByFitness(next)
+	return next
 }
 
-func (g *ga) calcFitness(
-	tt *ttv1.TimeTable,
-	dh *driverhub.DriverHub,
-	bus bus.Bus,
-) int {
-	drvs := make(map[uuid.UUID]struct{})
-	penalty := 0
-
-	ps := tt.GetEach(func(p path.Path) bool { return p.BusID == bus.ID })
-
-	for _, p := range ps {
-		if _, ok := drvs[p.DriverID]; ok {
-			d := dh.GetDriver(p.DriverID)
-			drvPaths := tt.GetEach(func(p path.Path) bool { return p.DriverID == d.ID() })
-
-			var (
-				workTimeAll       time.Duration
-				workTimeAfterRest time.Duration
-			)
-
-			for _, drvp := range drvPaths {
-				workTimeAll += drvp.EndTime.Sub(drvp.StartTime)
-				if drvp.EndTime.Before(p.StartTime) &&
-					drvp.StartTime.After(p.StartTime.Add(-d.WorkDur())) {
-					workTimeAfterRest += drvp.EndTime.Sub(drvp.StartTime)
-				}
-			}
-			if workTimeAll > d.WorkDur() {
-				penalty += 500
-			}
-			if workTimeAfterRest > d.WorkDur() {
-				penalty += 250
-			}
-
-			drvs[p.DriverID] = struct{}{}
+// tournament выбирает лучшую из TournamentSize случайных особей
+func (g *ga) tournament(population []*chromosome) *chromosome {
+	best := population[g.rnd.IntN(len(population))]
+	for i := 1; i < g.cfg.TournamentSize; i++ {
+		c := population[g.rnd.IntN(len(population))]
+		if c.fitness < best.fitness {
+			best = c
 		}
 	}
-
-	return len(drvs)*1000 - len(ps)*500 + penalty
+	return best
 }
 
-func (g *ga) selectForCrossover(
-	bs *station.BusStation,
-	dh *driverhub.DriverHub,
-	tt *ttv1.TimeTable,
-	n int,
-) []bus.Bus {
-	bsMap := bs.Buses()
-	sel := make([]bus.Bus, 0, len(bsMap))
-	n = min(n, len(bsMap))
-
-	for _, v := range bsMap {
-		sel = append(sel, v)
-	}
-	slices.SortFunc(sel, func(a, b bus.Bus) int {
-		if g.calcFitness(tt, dh, a) < g.calcFitness(tt, dh, b) {
+func sortByFitness(population []*chromosome) {
+	slices.SortStableFunc(population, func(a, b *chromosome) int {
+		switch {
+		case a.fitness < b.fitness:
 			return -1
+		case a.fitness > b.fitness:
+			return 1
 		}
-		return 1
+		return 0
 	})
-
-	return sel[:n]
 }
 
 func epochCounter(n int) func() (int, bool) {
@@ -0,0 +1,60 @@
+package gen_algorithm
+
+import (
+	"context"
+	"course/optimizer"
+	"course/optimizer/greedy"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// scene - восемь путей туда и обратно между двумя конечными через весь день
+func scene() (*ttv1.TimetableBuilder, *driverhub.DriverHubBuilder, *station.BusStationBuilder) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 8; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(time.Duration(6+2*i) * time.Hour)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(90 * time.Minute),
+		}, nil)
+	}
+	return ttb, driverhub.NewDriverHubBuilder(), station.NewBusStationBuilder()
+}
+
+// GA начинает с решения базового оптимизатора и сохраняет лучших,
+// поэтому не может вернуть решение хуже базового
+func TestGANotWorseThanBase(t *testing.T) {
+	ttb, dhb, bsb := scene()
+	base, err := greedy.NewGreedyOptimizer().Optimize(context.Background(), ttb.Build(), bsb.Build(), dhb.Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Population, cfg.Generations = 10, 20
+	res, err := New(greedy.NewGreedyOptimizer(), cfg).Optimize(context.Background(), ttb.Build(), bsb.Build(), dhb.Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != optimizer.StatusFeasible {
+		t.Errorf("Status = %s, want %s", res.Status, optimizer.StatusFeasible)
+	}
+	if res.Objective > base.Objective {
+		t.Errorf("Objective = %.2f, worse than the base %.2f", res.Objective, base.Objective)
+	}
+}
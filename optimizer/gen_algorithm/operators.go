@@ -0,0 +1,87 @@
+package gen_algorithm
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// crossover - скрещивание по сменам водителей с сохранением порядка.
+// Потомок целиком наследует случайную половину смен p1,
+// а оставшиеся пути получает сменами p2 в их исходном порядке.
+// Автобусы наследуются от того родителя, чья смена досталась пути.
+func (pr *problem) crossover(rnd *rand.Rand, p1, p2 *chromosome) *chromosome {
+	n := len(pr.trips)
+	child := &chromosome{
+		drv:  make([]int, n),
+		bus:  make([]int, n),
+		nbus: p1.nbus + p2.nbus - len(pr.buses),
+	}
+	taken := make([]bool, n)
+	usedSlot := make(map[int]bool)
+
+	for s, duty := range pr.duties(p1) {
+		if len(duty) == 0 || rnd.IntN(2) == 0 {
+			continue
+		}
+		slot := s
+		if s >= len(pr.drivers) {
+			slot = child.hire(pr, p1.hired[s-len(pr.drivers)])
+		}
+		usedSlot[slot] = true
+		for _, i := range duty {
+			child.drv[i] = slot
+			child.bus[i] = p1.bus[i]
+			taken[i] = true
+		}
+	}
+
+	for s, duty := range pr.duties(p2) {
+		rest := slices.DeleteFunc(slices.Clone(duty), func(i int) bool { return taken[i] })
+		if len(rest) == 0 {
+			continue
+		}
+		slot := s
+		switch {
+		case s >= len(pr.drivers):
+			slot = child.hire(pr, p2.hired[s-len(pr.drivers)])
+		case usedSlot[s]:
+			slot = child.hire(pr, pr.drivers[s].Type())
+		}
+		usedSlot[slot] = true
+		for _, i := range rest {
+			child.drv[i] = slot
+			child.bus[i] = p2.bus[i]
+			if p2.bus[i] >= len(pr.buses) {
+				// новые автобусы p2 идут после новых автобусов p1
+				child.bus[i] += p1.nbus - len(pr.buses)
+			}
+		}
+	}
+
+	return child
+}
+
+// swap меняет местами водителей или автобусы двух случайных путей
+func (pr *problem) swap(rnd *rand.Rand, c *chromosome) {
+	i, j := rnd.IntN(len(c.drv)), rnd.IntN(len(c.drv))
+	if rnd.IntN(2) == 0 {
+		c.drv[i], c.drv[j] = c.drv[j], c.drv[i]
+	} else {
+		c.bus[i], c.bus[j] = c.bus[j], c.bus[i]
+	}
+}
+
+// relocate переносит случайный путь к другому водителю или автобусу.
+// Изредка путь уходит новому водителю, чтобы разгрузить перегруженную смену.
+func (pr *problem) relocate(rnd *rand.Rand, c *chromosome) {
+	i := rnd.IntN(len(c.drv))
+	if rnd.IntN(2) == 0 {
+		c.bus[i] = rnd.IntN(c.nbus)
+		return
+	}
+	if rnd.IntN(10) == 0 {
+		c.drv[i] = c.hire(pr, pr.types[rnd.IntN(len(pr.types))])
+		return
+	}
+	c.drv[i] = c.drv[rnd.IntN(len(c.drv))]
+}
@@ -7,7 +7,7 @@ import (
 	"course/pkg/driverhub"
 	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable"
 	"github.com/google/uuid"
 	"log/slog"
 )
@@ -16,11 +16,11 @@ type greedy struct {
 }
 
 func NewGreedyOptimizer() optimizer.Optimizer {
-	return &greedy{}
+	return optimizer.Adapt(&greedy{})
 }
 
 func (g *greedy) Optimize(
-	tt *ttv1.TimeTable,
+	tt timetable.Timetable,
 	buses *station.BusStation,
 	drvs *driverhub.DriverHub,
 ) {
@@ -30,23 +30,22 @@ func (g *greedy) Optimize(
 	for _, pathID := range freePaths {
 		p := tt.GetPathByID(pathID)
 
-		b := buses.GetNotInWork(tt, p.StartTime)
+		b := buses.GetNotInWork(tt, p)
 		if b == nil {
-			b = bus.NewBus(uuid.New())
+			b = bus.NewBus(buses.NewID())
 			buses.Register(b)
 		}
 
-		drv := drvs.GetNotInWork(tt, p.StartTime)
+		drv := drvs.GetNotInWork(tt, p)
 
 		var possibleDrvs []driver.Driver
 
-		switch {
-		case drv == nil:
-			possibleDrvs = append(possibleDrvs, driver.NewDriverA(), driver.NewDriverB())
-			break
-		case drv.Type() == driver.DriverA:
-			possibleDrvs = append(possibleDrvs, drv)
-		case drv.Type() == driver.DriverB:
+		if drv == nil {
+			// примеряем путь к новому водителю каждого типа
+			for _, s := range driver.Types() {
+				possibleDrvs = append(possibleDrvs, driver.New(drvs.NewID(), s.Name))
+			}
+		} else {
 			possibleDrvs = append(possibleDrvs, drv)
 		}
 		paths := make([][]path.Path, len(possibleDrvs))
@@ -87,7 +86,7 @@ func (g *greedy) Optimize(
 			slog.Info(
 				"greedy-optimizer",
 				slog.String("driverID", bestDriver.ID().String()),
-				slog.Int("type", bestDriver.Type()),
+				slog.String("type", bestDriver.Type()),
 				slog.String("status", "hired"),
 			)
 			drvs.Register(bestDriver)
@@ -100,20 +99,21 @@ func (g *greedy) Optimize(
 
 	}
 
-	last := tt.GetEach(func(p path.Path) bool {
+	last := tt.GetFirstN(tt.PathsLen(), func(p path.Path) bool {
 		return p.BusID == uuid.Nil && p.DriverID == uuid.Nil
 	})
-	for _, p := range last {
-		d := drvs.GetNotInWork(tt, p.StartTime)
+	for _, id := range last {
+		p := tt.GetPathByID(id)
+		d := drvs.GetNotInWork(tt, p)
 		if d == nil {
-			d = driver.NewDriverA()
+			d = driver.New(drvs.NewID(), driver.Longest().Name)
 			drvs.Register(d)
 		}
 		tt.AssignDriverToPath(p.ID, d.ID())
 
-		b := buses.GetNotInWork(tt, p.StartTime)
+		b := buses.GetNotInWork(tt, p)
 		if b == nil {
-			b = bus.NewBus(uuid.New())
+			b = bus.NewBus(buses.NewID())
 			buses.Register(b)
 		}
 
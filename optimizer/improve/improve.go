@@ -0,0 +1,342 @@
+package improve
+
+import (
+	"context"
+	"course/config"
+	"course/optimizer"
+	"course/optimizer/localsearch"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Config - параметры локального поиска
+type Config struct {
+	// сколько раз подряд обходятся все окрестности, если улучшения продолжаются
+	MaxRounds int
+	// водители и автобусы, у которых путей не больше FewTrips, пробуют расформировать
+	FewTrips int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxRounds: 20,
+		FewTrips:  2,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.ImproveConfig) Config {
+	cfg := DefaultConfig()
+	if c.MaxRounds > 0 {
+		cfg.MaxRounds = c.MaxRounds
+	}
+	if c.FewTrips > 0 {
+		cfg.FewTrips = c.FewTrips
+	}
+	return cfg
+}
+
+type improve struct {
+	opt optimizer.Optimizer
+	cfg Config
+}
+
+// New - локальный поиск поверх решения оптимизатора opt.
+// Обходит окрестности: перенос пути к другому водителю или автобусу,
+// обмен хвостами смен (2-opt) и расформирование малозагруженных водителей
+// и автобусов. Улучшающий ход принимается сразу; поиск заканчивается,
+// когда ни одна окрестность не дает улучшения.
+func New(opt optimizer.Optimizer, cfg Config) optimizer.Optimizer {
+	return &improve{opt: opt, cfg: cfg}
+}
+
+// search - состояние одного запуска: текущее решение и его штрафная стоимость
+type search struct {
+	ctx    context.Context
+	s      *localsearch.State
+	energy float64
+	tried  int
+}
+
+// neighborhood обходит ходы одной окрестности и возвращает, было ли улучшение
+type neighborhood func(ls *search, cfg Config) bool
+
+func (im *improve) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	base, err := im.opt.Optimize(ctx, tt, buses, drvs)
+	if err != nil {
+		return base, err
+	}
+
+	ls := &search{
+		ctx:    ctx,
+		s:      &localsearch.State{TT: tt, Buses: buses, Drivers: drvs},
+		energy: optimizer.Penalized(tt, buses, drvs),
+	}
+	neighborhoods := []neighborhood{
+		dissolveDrivers,
+		dissolveBuses,
+		relocateDrivers,
+		relocateBuses,
+		swapTails,
+	}
+
+	for round := 0; round < im.cfg.MaxRounds && ctx.Err() == nil; round++ {
+		improved := false
+		for _, n := range neighborhoods {
+			if n(ls, im.cfg) {
+				improved = true
+			}
+		}
+		optimizer.Notify(ctx, optimizer.Event{
+			Optimizer: "improve",
+			Epoch:     round + 1,
+			Best:      ls.energy,
+			Current:   ls.energy,
+			Drivers:   len(drvs.Drivers()),
+			Buses:     len(buses.Buses()),
+			Elapsed:   time.Since(start),
+		})
+		if !improved {
+			break
+		}
+	}
+
+	buses.AllocateDepots(tt)
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = base.Iterations + ls.tried
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
+	}
+	return res, nil
+}
+
+// try применяет ход в транзакции и оставляет его, только если штрафная
+// стоимость уменьшилась. Ход, вернувший false, отменяется и не считается.
+func (ls *search) try(apply func() bool) bool {
+	ls.s.Begin()
+	if !apply() {
+		ls.s.Rollback()
+		return false
+	}
+	ls.tried++
+	next := optimizer.Penalized(ls.s.TT, ls.s.Buses, ls.s.Drivers)
+	if next < ls.energy {
+		ls.s.Commit()
+		ls.energy = next
+		return true
+	}
+	ls.s.Rollback()
+	return false
+}
+
+func (ls *search) done() bool {
+	return ls.ctx.Err() != nil
+}
+
+// relocateDrivers переносит каждый путь к другому водителю штата, свободному на это время
+func relocateDrivers(ls *search, _ Config) bool {
+	improved := false
+	for _, pid := range ls.s.PathIDs(func(p path.Path) bool { return true }) {
+		for _, did := range ls.s.DriverIDs() {
+			if ls.done() {
+				return improved
+			}
+			p := ls.s.TT.GetPathByID(pid)
+			d := ls.s.Drivers.GetDriver(did)
+			if d == nil || did == p.DriverID || !ls.s.CanTake(d, p, uuid.Nil) {
+				continue
+			}
+			if ls.try(func() bool { ls.s.AssignDriver(pid, d); return true }) {
+				improved = true
+				break
+			}
+		}
+	}
+	return improved
+}
+
+// relocateBuses переносит каждый путь на другой автобус парка,
+// свободный на это время и стоящий в начальной точке пути
+func relocateBuses(ls *search, _ Config) bool {
+	improved := false
+	for _, pid := range ls.s.PathIDs(func(p path.Path) bool { return true }) {
+		for _, bid := range ls.s.BusIDs() {
+			if ls.done() {
+				return improved
+			}
+			p := ls.s.TT.GetPathByID(pid)
+			b := ls.s.Buses.GetBus(bid)
+			if b == nil || bid == p.BusID || !ls.s.BusFree(bid, p) {
+				continue
+			}
+			if ls.try(func() bool { ls.s.AssignBus(pid, b); return true }) {
+				improved = true
+				break
+			}
+		}
+	}
+	return improved
+}
+
+// swapTails - 2-opt для смен: водители d1 и d2 обмениваются всеми путями,
+// начинающимися не раньше начала одного из путей d1.
+// Пробуются только обмены, после которых смены не пересекаются.
+func swapTails(ls *search, _ Config) bool {
+	improved := false
+	for _, id1 := range ls.s.DriverIDs() {
+		for _, cut := range ls.s.PathIDs(func(p path.Path) bool { return p.DriverID == id1 }) {
+			for _, id2 := range ls.s.DriverIDs() {
+				if ls.done() {
+					return improved
+				}
+				d1, d2 := ls.s.Drivers.GetDriver(id1), ls.s.Drivers.GetDriver(id2)
+				if d1 == nil || d2 == nil || id1 == id2 {
+					continue
+				}
+				p := ls.s.TT.GetPathByID(cut)
+				if p.DriverID != id1 {
+					continue
+				}
+				from := p.StartTime
+
+				head1, tail1 := split(ls.s, id1, from)
+				head2, tail2 := split(ls.s, id2, from)
+				if !fits(head1, tail2) || !fits(head2, tail1) {
+					continue
+				}
+
+				ok := ls.try(func() bool {
+					for _, t := range tail1 {
+						ls.s.AssignDriver(t.ID, d2)
+					}
+					for _, t := range tail2 {
+						ls.s.AssignDriver(t.ID, d1)
+					}
+					return true
+				})
+				if ok {
+					improved = true
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// split делит пути водителя на начатые до from и остальные
+func split(s *localsearch.State, driverID uuid.UUID, from time.Time) (head, tail []path.Path) {
+	for _, id := range s.PathIDs(func(p path.Path) bool { return p.DriverID == driverID }) {
+		p := s.TT.GetPathByID(id)
+		if p.StartTime.Before(from) {
+			head = append(head, p)
+		} else {
+			tail = append(tail, p)
+		}
+	}
+	return head, tail
+}
+
+// fits - можно ли поставить tail после head без пересечения путей
+func fits(head, tail []path.Path) bool {
+	if len(head) == 0 || len(tail) == 0 {
+		return true
+	}
+	end := head[0].EndTime
+	for _, p := range head[1:] {
+		if p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	return !tail[0].StartTime.Before(end)
+}
+
+// dissolveDrivers раздает пути малозагруженного водителя остальным;
+// если взять путь некому, водитель остается
+func dissolveDrivers(ls *search, cfg Config) bool {
+	improved := false
+	for _, id := range ls.s.DriverIDs() {
+		if ls.done() {
+			return improved
+		}
+		own := ls.s.PathIDs(func(p path.Path) bool { return p.DriverID == id })
+		if len(own) == 0 || len(own) > cfg.FewTrips {
+			continue
+		}
+
+		// ход невозможен, если хотя бы один путь взять некому
+		dissolve := func() bool {
+			for _, pid := range own {
+				p := ls.s.TT.GetPathByID(pid)
+				taken := false
+				for _, other := range ls.s.DriverIDs() {
+					d := ls.s.Drivers.GetDriver(other)
+					if other == id || d == nil || !ls.s.CanTake(d, p, uuid.Nil) {
+						continue
+					}
+					ls.s.AssignDriver(pid, d)
+					taken = true
+					break
+				}
+				if !taken {
+					return false
+				}
+			}
+			return true
+		}
+		if ls.try(dissolve) {
+			improved = true
+		}
+	}
+	return improved
+}
+
+// dissolveBuses раздает пути малозагруженного автобуса остальным автобусам парка
+func dissolveBuses(ls *search, cfg Config) bool {
+	improved := false
+	for _, id := range ls.s.BusIDs() {
+		if ls.done() {
+			return improved
+		}
+		own := ls.s.PathIDs(func(p path.Path) bool { return p.BusID == id })
+		if len(own) == 0 || len(own) > cfg.FewTrips {
+			continue
+		}
+
+		// ход невозможен, если хотя бы один путь взять некому
+		dissolve := func() bool {
+			for _, pid := range own {
+				p := ls.s.TT.GetPathByID(pid)
+				taken := false
+				for _, other := range ls.s.BusIDs() {
+					b := ls.s.Buses.GetBus(other)
+					if other == id || b == nil || !ls.s.BusFree(other, p) {
+						continue
+					}
+					ls.s.AssignBus(pid, b)
+					taken = true
+					break
+				}
+				if !taken {
+					return false
+				}
+			}
+			return true
+		}
+		if ls.try(dissolve) {
+			improved = true
+		}
+	}
+	return improved
+}
@@ -0,0 +1,79 @@
+package improve
+
+import (
+	"context"
+	"course/optimizer"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"course/validate"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// perTrip - базовое решение: у каждого пути свои водитель типа A и автобус
+type perTrip struct{}
+
+func (perTrip) Optimize(
+	_ context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	for id, p := range tt.GetEach(func(p path.Path) bool { return true }) {
+		d := driver.New(drvs.NewID(), "A")
+		drvs.Register(d)
+		b := bus.NewBus(uuid.UUID{0xb0, byte(p.Number)})
+		buses.Register(b)
+		tt.AssignDriverToPath(id, d.ID())
+		tt.AssignBusToPath(id, b.ID)
+	}
+	return optimizer.Summarize(tt, buses, drvs), nil
+}
+
+// четыре часовых пути туда и обратно через полчаса друг за другом
+// проезжает один водитель на одном автобусе
+func TestImproveMergesDutiesAndBlocks(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 4; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(6*time.Hour + time.Duration(i)*90*time.Minute)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+		}, nil)
+	}
+	base, _ := perTrip{}.Optimize(context.Background(), ttb.Build(), station.NewBusStationBuilder().Build(),
+		driverhub.NewDriverHubBuilder().Build())
+
+	tt := ttb.Build()
+	buses := station.NewBusStationBuilder().Build()
+	drvs := driverhub.NewDriverHubBuilder().Build()
+	res, err := New(perTrip{}, DefaultConfig()).Optimize(context.Background(), tt, buses, drvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Objective >= base.Objective {
+		t.Errorf("Objective = %.2f, want below the base %.2f", res.Objective, base.Objective)
+	}
+	if res.Drivers != 1 || res.Buses != 1 {
+		t.Errorf("Drivers, Buses = %d, %d, want 1, 1", res.Drivers, res.Buses)
+	}
+	if vs := validate.Validate(tt, drvs, buses); len(vs) > 0 {
+		t.Errorf("Validate = %v", vs)
+	}
+}
@@ -0,0 +1,204 @@
+package localsearch
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+)
+
+// State - текущее решение, которое меняют ходы.
+// Пробный ход делается в транзакции: Begin, ход, затем Commit или Rollback.
+type State struct {
+	TT      timetable.Timetable
+	Buses   *station.BusStation
+	Drivers *driverhub.DriverHub
+
+	// изменения штата открытых транзакций и их начала
+	staff []staffChange
+	marks []int
+}
+
+// DriverIDs - водители в штате в стабильном порядке
+func (s *State) DriverIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for id := range s.Drivers.Drivers() {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
+
+// BusIDs - автобусы парка в стабильном порядке
+func (s *State) BusIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for id := range s.Buses.Buses() {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
+
+// PathIDs - пути в порядке начала
+func (s *State) PathIDs(fn func(p path.Path) bool) []uuid.UUID {
+	ps := make([]path.Path, 0)
+	for _, p := range s.TT.GetEach(fn) {
+		ps = append(ps, p)
+	}
+	slices.SortFunc(ps, func(a, b path.Path) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	ids := make([]uuid.UUID, len(ps))
+	for i, p := range ps {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// AssignDriver назначает путь водителю d, при необходимости нанимая его.
+// Водитель, у которого не осталось путей, увольняется.
+func (s *State) AssignDriver(pathID uuid.UUID, d driver.Driver) {
+	old := s.TT.GetPathByID(pathID).DriverID
+	if s.Drivers.GetDriver(d.ID()) == nil {
+		s.Drivers.Register(d)
+		s.record(staffChange{driver: d, added: true})
+	}
+	s.TT.AssignDriverToPath(pathID, d.ID())
+
+	if old != uuid.Nil && old != d.ID() && len(s.TT.DriverPaths(old)) == 0 {
+		s.record(staffChange{driver: s.Drivers.GetDriver(old)})
+		s.Drivers.Unregister(old)
+	}
+}
+
+// AssignBus назначает путь автобусу b, при необходимости закупая его.
+// Автобус, у которого не осталось путей, списывается.
+func (s *State) AssignBus(pathID uuid.UUID, b *bus.Bus) {
+	old := s.TT.GetPathByID(pathID).BusID
+	if s.Buses.GetBus(b.ID) == nil {
+		s.Buses.Register(b)
+		s.record(staffChange{bus: b, added: true})
+	}
+	s.TT.AssignBusToPath(pathID, b.ID)
+
+	if old != uuid.Nil && old != b.ID && len(s.TT.BusPaths(old)) == 0 {
+		s.record(staffChange{bus: s.Buses.GetBus(old)})
+		s.Buses.Unregister(old)
+	}
+}
+
+// staffChange - найм или увольнение водителя, закупка или списание автобуса
+type staffChange struct {
+	driver driver.Driver
+	bus    *bus.Bus
+	added  bool
+}
+
+func (s *State) record(c staffChange) {
+	if len(s.marks) > 0 {
+		s.staff = append(s.staff, c)
+	}
+}
+
+// Begin открывает транзакцию пробного хода: вместе с транзакцией расписания
+// запоминаются нанятые и уволенные водители, закупленные и списанные автобусы
+func (s *State) Begin() {
+	s.TT.Begin()
+	s.marks = append(s.marks, len(s.staff))
+}
+
+// Commit оставляет изменения последней транзакции
+func (s *State) Commit() {
+	if len(s.marks) == 0 {
+		return
+	}
+	s.TT.Commit()
+	s.marks = s.marks[:len(s.marks)-1]
+	if len(s.marks) == 0 {
+		s.staff = s.staff[:0]
+	}
+}
+
+// Rollback отменяет изменения последней транзакции: назначения путей и штат
+func (s *State) Rollback() {
+	if len(s.marks) == 0 {
+		return
+	}
+	m := s.marks[len(s.marks)-1]
+	s.marks = s.marks[:len(s.marks)-1]
+	for i := len(s.staff) - 1; i >= m; i-- {
+		c := s.staff[i]
+		switch {
+		case c.driver != nil && c.added:
+			s.Drivers.Unregister(c.driver.ID())
+		case c.driver != nil:
+			s.Drivers.Register(c.driver)
+		case c.added:
+			s.Buses.Unregister(c.bus.ID)
+		default:
+			s.Buses.Register(c.bus)
+		}
+	}
+	s.staff = s.staff[:m]
+	s.TT.Rollback()
+}
+
+// DriverFree - нет ли у водителя путей, пересекающихся с p
+func (s *State) DriverFree(id uuid.UUID, p path.Path) bool {
+	for _, o := range s.TT.DriverPaths(id) {
+		if overlaps(o, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// BusFree - свободен ли автобус на время p и успевает ли он после предыдущего
+// пути перегоном к началу p
+func (s *State) BusFree(id uuid.UUID, p path.Path) bool {
+	var (
+		prev  path.Path
+		found bool
+	)
+	for _, o := range s.TT.BusPaths(id) {
+		if o.ID == p.ID {
+			continue
+		}
+		if overlaps(o, p) {
+			return false
+		}
+		if !o.EndTime.After(p.StartTime) && (!found || o.EndTime.After(prev.EndTime)) {
+			prev, found = o, true
+		}
+	}
+	return !found || s.TT.Follows(prev, p)
+}
+
+func overlaps(a, b path.Path) bool {
+	return a.ID != b.ID && a.StartTime.Before(b.EndTime) && b.StartTime.Before(a.EndTime)
+}
+
+// CanTake - может ли водитель d взять путь p без пересечений
+// и не нарушая правил своего типа (NeedsRest).
+// Путь except не учитывается: так проверяется обмен путями.
+func (s *State) CanTake(d driver.Driver, p path.Path, except uuid.UUID) bool {
+	ps := []path.Path{p}
+	for _, o := range s.TT.DriverPaths(d.ID()) {
+		if o.ID == p.ID || o.ID == except {
+			continue
+		}
+		if overlaps(o, p) {
+			return false
+		}
+		ps = append(ps, o)
+	}
+	return !d.NeedsRest(ps)
+}
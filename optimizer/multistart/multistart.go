@@ -0,0 +1,152 @@
+package multistart
+
+import (
+	"context"
+	"course/config"
+	"course/cost"
+	"course/optimizer"
+	"course/pkg/driverhub"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"course/scene"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Scene - сцена эксперимента, из которой строятся независимые копии для запусков
+type Scene struct {
+	TT      *ttv1.TimetableBuilder
+	Drivers *driverhub.DriverHubBuilder
+	Buses   *station.BusStationBuilder
+}
+
+// Factory создает оптимизатор для одного запуска с зерном seed
+type Factory func(seed uint64) optimizer.Optimizer
+
+// Config - параметры мультистарта
+type Config struct {
+	// число независимых запусков
+	Starts int
+	// зерно первого запуска, следующие получают Seed+1, Seed+2...; 0 - случайное
+	Seed uint64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Starts: 4,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.MultiStartConfig) Config {
+	cfg := DefaultConfig()
+	if c.Starts > 0 {
+		cfg.Starts = c.Starts
+	}
+	cfg.Seed = c.Seed
+	return cfg
+}
+
+type multiStart struct {
+	scene   Scene
+	factory Factory
+	cfg     Config
+}
+
+// New - параллельный мультистарт. Каждый из Starts запусков получает свою копию
+// сцены, собранную билдерами (расписание - через scene.Timetable), и свой
+// оптимизатор от factory со своим зерном.
+// Лучшее по стоимости cost.M() решение переносится в переданные tt, buses и drvs,
+// а разброс запусков по той же стоимости, что и Result.Objective,
+// возвращается в Result.Spread.
+// Копии штата и парка собираются по порядку запусков, и каждая выдает
+// идентификаторы новых водителей и автобусов из своего генератора, поэтому
+// мультистарт по зерну и зерну билдеров повторяется, хотя запуски идут одновременно.
+func New(scene Scene, factory Factory, cfg Config) optimizer.Optimizer {
+	return &multiStart{scene: scene, factory: factory, cfg: cfg}
+}
+
+// run - итог одного запуска на своей копии сцены
+type run struct {
+	res       optimizer.Result
+	err       error
+	objective float64
+	snap      *optimizer.Snapshot
+}
+
+func (m *multiStart) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	seed := m.cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	runs := make([]run, m.cfg.Starts)
+	var wg sync.WaitGroup
+	for i := range runs {
+		ctt, cbs, cdh := scene.Timetable(m.scene.TT), m.scene.Buses.Build(), m.scene.Drivers.Build()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rctx := ctx
+			if obs := optimizer.ObserverFrom(ctx); obs != nil {
+				// события запусков различаются по номеру после имени оптимизатора
+				rctx = optimizer.WithObserver(ctx, func(ev optimizer.Event) {
+					ev.Optimizer = fmt.Sprintf("%s#%d", ev.Optimizer, i+1)
+					obs(ev)
+				})
+			}
+			res, err := m.factory(seed+uint64(i)).Optimize(rctx, ctt, cbs, cdh)
+			runs[i] = run{
+				res:       res,
+				err:       err,
+				objective: cost.M().Score(ctt, cdh, cbs).Total(),
+				snap:      optimizer.TakeSnapshot(ctt, cbs, cdh),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var (
+		best       *run
+		objectives []float64
+		err        error
+		iters      int
+	)
+	for i := range runs {
+		r := &runs[i]
+		if r.err != nil {
+			err = r.err
+			continue
+		}
+		iters += r.res.Iterations
+		objectives = append(objectives, r.objective)
+		if best == nil || r.objective < best.objective {
+			best = r
+		}
+	}
+	if best == nil {
+		return optimizer.Result{Status: optimizer.StatusTimedOut}, err
+	}
+
+	best.snap.Restore(tt, buses, drvs)
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = iters
+	res.WallTime = time.Since(start)
+	res.LowerBound = best.res.LowerBound
+	res.Spread = optimizer.NewSpread(objectives)
+	if best.res.Status == optimizer.StatusTimedOut || best.res.Status == optimizer.StatusOptimal {
+		res.Status = best.res.Status
+	}
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package multistart
+
+import (
+	"context"
+	"course/optimizer"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"math"
+	"testing"
+	"time"
+)
+
+// bySeed - оптимизатор, качество которого зависит от зерна: при нечетном
+// зерне все пути ведет один водитель, при четном у каждого пути свой
+type bySeed uint64
+
+func (s bySeed) Optimize(
+	_ context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	b := bus.NewBus(uuid.UUID{0xb1})
+	buses.Register(b)
+	var d driver.Driver
+	for id := range tt.GetEach(func(p path.Path) bool { return true }) {
+		if d == nil || s%2 == 0 {
+			d = driver.New(drvs.NewID(), "A")
+			drvs.Register(d)
+		}
+		tt.AssignDriverToPath(id, d.ID())
+		tt.AssignBusToPath(id, b.ID)
+	}
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = 1
+	return res, nil
+}
+
+// лучший запуск переносится в сцену, а разброс считается по Objective
+func TestMultiStartPicksBestRun(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 4; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(6*time.Hour + time.Duration(i)*90*time.Minute)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+		}, nil)
+	}
+	sc := Scene{TT: ttb, Drivers: driverhub.NewDriverHubBuilder(), Buses: station.NewBusStationBuilder()}
+
+	cfg := Config{Starts: 3, Seed: 2}
+	factory := func(seed uint64) optimizer.Optimizer { return bySeed(seed) }
+	tt, buses, drvs := ttb.Build(), sc.Buses.Build(), sc.Drivers.Build()
+	res, err := New(sc, factory, cfg).Optimize(context.Background(), tt, buses, drvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Drivers != 1 {
+		t.Errorf("Drivers = %d, want the best run with 1", res.Drivers)
+	}
+	if res.Iterations != cfg.Starts {
+		t.Errorf("Iterations = %d, want %d", res.Iterations, cfg.Starts)
+	}
+	sp := res.Spread
+	if sp.Runs != cfg.Starts {
+		t.Errorf("Spread.Runs = %d, want %d", sp.Runs, cfg.Starts)
+	}
+	if math.Abs(sp.Best-res.Objective) > 1e-9 {
+		t.Errorf("Spread.Best = %v, want Objective %v", sp.Best, res.Objective)
+	}
+	if sp.Worst <= sp.Best {
+		t.Errorf("Spread.Worst = %v, want above Best %v", sp.Worst, sp.Best)
+	}
+}
@@ -0,0 +1,46 @@
+package optimizer
+
+import (
+	"context"
+	"time"
+)
+
+// Event - состояние итеративного оптимизатора после одной эпохи
+type Event struct {
+	// имя оптимизатора, приславшего событие: декоратор и его базовый
+	// оптимизатор шлют события в один и тот же Observer
+	Optimizer string
+	Epoch     int
+	// лучшая найденная штрафная стоимость
+	Best float64
+	// стоимость текущего решения; у популяционных методов - средняя по популяции
+	Current float64
+	// водители и автобусы, занятые в лучшем решении
+	Drivers int
+	Buses   int
+	Elapsed time.Duration
+}
+
+// Observer получает события оптимизатора. Мультистарт вызывает его
+// из нескольких горутин одновременно.
+type Observer func(Event)
+
+type observerKey struct{}
+
+// WithObserver - контекст, оптимизаторы под которым сообщают о ходе работы obs
+func WithObserver(ctx context.Context, obs Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, obs)
+}
+
+// ObserverFrom - наблюдатель контекста или nil
+func ObserverFrom(ctx context.Context) Observer {
+	obs, _ := ctx.Value(observerKey{}).(Observer)
+	return obs
+}
+
+// Notify передает событие наблюдателю контекста, если он есть
+func Notify(ctx context.Context, ev Event) {
+	if obs := ObserverFrom(ctx); obs != nil {
+		obs(ev)
+	}
+}
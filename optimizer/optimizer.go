@@ -1,15 +1,171 @@
 package optimizer
 
 import (
+	"context"
+	"course/cost"
 	"course/pkg/driverhub"
+	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable"
+	"math"
+	"time"
 )
 
+// Optimizer - оптимизатор расписания с бюджетом времени.
+// Оптимизатор обязан прекратить работу, когда ctx завершен,
+// и вернуть лучшее найденное к этому моменту решение.
 type Optimizer interface {
 	Optimize(
-		tt *ttv1.TimeTable,
+		ctx context.Context,
+		tt timetable.Timetable,
+		buses *station.BusStation,
+		drvs *driverhub.DriverHub,
+	) (Result, error)
+}
+
+// Legacy - оптимизатор старого образца: его нельзя прервать,
+// и он ничего не сообщает о результате
+type Legacy interface {
+	Optimize(
+		tt timetable.Timetable,
 		buses *station.BusStation,
 		drvs *driverhub.DriverHub,
 	)
 }
+
+// Status - причина, по которой оптимизатор остановился
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	// StatusOptimal - решение доказано оптимально
+	StatusOptimal
+	// StatusFeasible - все пути назначены, оптимальность не доказана
+	StatusFeasible
+	// StatusInfeasible - часть путей осталась без водителя или автобуса
+	StatusInfeasible
+	// StatusTimedOut - бюджет времени исчерпан до завершения работы
+	StatusTimedOut
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOptimal:
+		return "optimal"
+	case StatusFeasible:
+		return "feasible"
+	case StatusInfeasible:
+		return "infeasible"
+	case StatusTimedOut:
+		return "timed out"
+	default:
+		return "unknown"
+	}
+}
+
+// Result - итог работы оптимизатора
+type Result struct {
+	Status     Status
+	Objective  float64
+	Drivers    int
+	Buses      int
+	Iterations int
+	WallTime   time.Duration
+	// нижняя граница Objective, которую доказал оптимизатор; 0 - неизвестна
+	LowerBound float64
+	// разброс независимых запусков; пустой, если запуск был один
+	Spread Spread
+}
+
+// Spread - разброс Objective по нескольким запускам
+type Spread struct {
+	Runs   int
+	Best   float64
+	Worst  float64
+	Mean   float64
+	StdDev float64
+}
+
+// NewSpread считает разброс по значениям отдельных запусков
+func NewSpread(values []float64) Spread {
+	if len(values) == 0 {
+		return Spread{}
+	}
+	sp := Spread{Runs: len(values), Best: values[0], Worst: values[0]}
+	for _, v := range values {
+		sp.Best = min(sp.Best, v)
+		sp.Worst = max(sp.Worst, v)
+		sp.Mean += v
+	}
+	sp.Mean /= float64(len(values))
+	for _, v := range values {
+		sp.StdDev += (v - sp.Mean) * (v - sp.Mean)
+	}
+	sp.StdDev = math.Sqrt(sp.StdDev / float64(len(values)))
+	return sp
+}
+
+// Gap - относительный разрыв между найденным решением и нижней границей.
+// ok == false, если оптимизатор нижнюю границу не дает.
+func (r Result) Gap() (gap float64, ok bool) {
+	if r.LowerBound <= 0 || r.Objective <= 0 {
+		return 0, false
+	}
+	return max(r.Objective-r.LowerBound, 0) / r.Objective, true
+}
+
+// Summarize собирает Result по уже оптимизированному расписанию.
+// Iterations и WallTime заполняет вызывающий.
+func Summarize(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) Result {
+	res := Result{
+		Status:  StatusFeasible,
+		Drivers: len(drvs.Drivers()),
+		Buses:   len(buses.Buses()),
+	}
+	res.Objective = cost.M().Score(tt, drvs, buses).Total()
+
+	unplanned := tt.GetFirstN(1, func(p path.Path) bool { return !p.IsPlanned() })
+	if len(unplanned) > 0 {
+		res.Status = StatusInfeasible
+	}
+	return res
+}
+
+type adapter struct {
+	opt Legacy
+}
+
+// Adapt оборачивает Legacy оптимизатор в Optimizer.
+// Legacy оптимизатор нельзя прервать, поэтому контекст проверяется
+// до запуска и после него: если бюджет истек во время работы,
+// результат помечается как StatusTimedOut.
+func Adapt(opt Legacy) Optimizer {
+	return &adapter{opt: opt}
+}
+
+func (a *adapter) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{Status: StatusTimedOut}, err
+	}
+
+	start := time.Now()
+	a.opt.Optimize(tt, buses, drvs)
+	buses.AllocateDepots(tt)
+
+	res := Summarize(tt, buses, drvs)
+	res.Iterations = 1
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = StatusTimedOut
+	}
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package optimizer
+
+import (
+	"context"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// legacy - оптимизатор старого образца, который назначает всем путям
+// одного водителя и один автобус или ничего не делает
+type legacy struct {
+	assign bool
+	called bool
+}
+
+func (l *legacy) Optimize(tt timetable.Timetable, _ *station.BusStation, _ *driverhub.DriverHub) {
+	l.called = true
+	if !l.assign {
+		return
+	}
+	for id := range tt.GetEach(func(p path.Path) bool { return true }) {
+		tt.AssignDriverToPath(id, uuid.UUID{1})
+		tt.AssignBusToPath(id, uuid.UUID{2})
+	}
+}
+
+func TestAdapt(t *testing.T) {
+	start := time.Date(2024, time.November, 25, 8, 0, 0, 0, time.UTC)
+	ttb := ttv1.NewBuilder()
+	ttb.AddPath(path.Path{
+		ID:        uuid.UUID{3},
+		Points:    []path.Point{{Id: uuid.UUID{4}, IsBusStation: true}, {Id: uuid.UUID{5}, IsBusStation: true}},
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+	}, nil)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		assign bool
+		status Status
+		called bool
+		err    error
+	}{
+		{"all paths assigned", context.Background(), true, StatusFeasible, true, nil},
+		{"paths left unassigned", context.Background(), false, StatusInfeasible, true, nil},
+		{"budget spent before the start", canceled, true, StatusTimedOut, false, context.Canceled},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &legacy{assign: tc.assign}
+			res, err := Adapt(l).Optimize(tc.ctx, ttb.Build(),
+				station.NewBusStationBuilder().Build(), driverhub.NewDriverHubBuilder().Build())
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if res.Status != tc.status {
+				t.Errorf("Status = %s, want %s", res.Status, tc.status)
+			}
+			if l.called != tc.called {
+				t.Errorf("legacy called = %v, want %v", l.called, tc.called)
+			}
+		})
+	}
+}
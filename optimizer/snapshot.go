@@ -0,0 +1,85 @@
+package optimizer
+
+import (
+	"course/cost"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/validate"
+	"github.com/google/uuid"
+)
+
+// Penalized - стоимость расписания по cost.M() плюс штраф Uncovered
+// за каждое нарушение жестких ограничений. Ее минимизируют локальные поиски,
+// которым приходится проходить через недопустимые решения.
+func Penalized(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) float64 {
+	m := cost.M()
+	vs := validate.Validate(tt, drvs, buses)
+	return m.Score(tt, drvs, buses).Total() + float64(len(vs))*m.Weights().Uncovered
+}
+
+// Snapshot - сохраненное назначение путей вместе со штатом водителей и автобусов
+type Snapshot struct {
+	drivers map[uuid.UUID]driver.Driver
+	buses   map[uuid.UUID]bus.Bus
+	assign  map[uuid.UUID][2]uuid.UUID
+}
+
+func TakeSnapshot(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) *Snapshot {
+	s := &Snapshot{
+		drivers: drvs.Drivers(),
+		buses:   buses.Buses(),
+		assign:  make(map[uuid.UUID][2]uuid.UUID),
+	}
+	for id, p := range tt.GetEach(func(p path.Path) bool { return true }) {
+		s.assign[id] = [2]uuid.UUID{p.DriverID, p.BusID}
+	}
+	return s
+}
+
+// Drivers - число водителей в сохраненном штате
+func (s *Snapshot) Drivers() int { return len(s.drivers) }
+
+// Buses - число автобусов в сохраненном парке
+func (s *Snapshot) Buses() int { return len(s.buses) }
+
+// Restore возвращает расписание, водителей и автобусы к сохраненному состоянию
+func (s *Snapshot) Restore(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) {
+	for id := range drvs.Drivers() {
+		if _, ok := s.drivers[id]; !ok {
+			drvs.Unregister(id)
+		}
+	}
+	for _, d := range s.drivers {
+		drvs.Register(d)
+	}
+
+	for id := range buses.Buses() {
+		if _, ok := s.buses[id]; !ok {
+			buses.Unregister(id)
+		}
+	}
+	for _, b := range s.buses {
+		buses.Register(&b)
+	}
+
+	for id, a := range s.assign {
+		tt.AssignDriverToPath(id, a[0])
+		tt.AssignBusToPath(id, a[1])
+	}
+}
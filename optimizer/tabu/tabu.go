@@ -0,0 +1,232 @@
+package tabu
+
+import (
+	"context"
+	"course/config"
+	"course/optimizer"
+	"course/optimizer/localsearch"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Config - параметры поиска с запретами
+type Config struct {
+	Iterations int
+	// сколько итераций пара (путь, водитель) остается под запретом
+	Tenure int
+	// сколько соседних решений оценивается на каждой итерации
+	Candidates int
+	// 0 - случайное зерно
+	Seed uint64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Iterations: 200,
+		Tenure:     15,
+		Candidates: 20,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.TabuConfig) Config {
+	cfg := DefaultConfig()
+	if c.Iterations > 0 {
+		cfg.Iterations = c.Iterations
+	}
+	if c.Tenure > 0 {
+		cfg.Tenure = c.Tenure
+	}
+	if c.Candidates > 0 {
+		cfg.Candidates = c.Candidates
+	}
+	cfg.Seed = c.Seed
+	return cfg
+}
+
+type tabu struct {
+	opt optimizer.Optimizer
+	cfg Config
+}
+
+// New - поиск с запретами, улучшающий смены водителей решения оптимизатора opt.
+// Ходы - перенос пути к другому водителю и обмен путями двух водителей,
+// водитель, оставшийся без путей, увольняется. Автобусы не меняются.
+func New(opt optimizer.Optimizer, cfg Config) optimizer.Optimizer {
+	return &tabu{opt: opt, cfg: cfg}
+}
+
+// move - перенос путей trips к водителям to (обмен - два переноса)
+type move struct {
+	trips []uuid.UUID
+	to    []driver.Driver
+}
+
+func (m move) apply(s *localsearch.State) {
+	for i := range m.trips {
+		s.AssignDriver(m.trips[i], m.to[i])
+	}
+}
+
+type tabuKey struct {
+	trip   uuid.UUID
+	driver uuid.UUID
+}
+
+func (t *tabu) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	start := time.Now()
+
+	base, err := t.opt.Optimize(ctx, tt, buses, drvs)
+	if err != nil {
+		return base, err
+	}
+	iterations := base.Iterations
+
+	seed := t.cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rnd := rand.New(rand.NewPCG(seed, seed))
+
+	s := &localsearch.State{TT: tt, Buses: buses, Drivers: drvs}
+	bestEnergy := optimizer.Penalized(tt, buses, drvs)
+	best := optimizer.TakeSnapshot(tt, buses, drvs)
+
+	// пара (путь, водитель) запрещена до указанной итерации
+	tabuList := make(map[tabuKey]int)
+
+	for it := 0; it < t.cfg.Iterations && ctx.Err() == nil; it++ {
+		iterations++
+
+		var (
+			chosen     *move
+			chosenCost = math.Inf(1)
+		)
+		for _, m := range t.candidates(s, rnd) {
+			s.Begin()
+			m.apply(s)
+			c := optimizer.Penalized(tt, buses, drvs)
+			s.Rollback()
+
+			forbidden := false
+			for i := range m.trips {
+				if until, ok := tabuList[tabuKey{m.trips[i], m.to[i].ID()}]; ok && until > it {
+					forbidden = true
+				}
+			}
+			// критерий стремления: запрет снимается, если ход дает новый рекорд
+			if forbidden && c >= bestEnergy {
+				continue
+			}
+			if c < chosenCost {
+				chosen, chosenCost = &m, c
+			}
+		}
+		if chosen == nil {
+			continue
+		}
+
+		for _, id := range chosen.trips {
+			from := tt.GetPathByID(id).DriverID
+			tabuList[tabuKey{id, from}] = it + t.cfg.Tenure
+		}
+		chosen.apply(s)
+
+		if chosenCost < bestEnergy {
+			bestEnergy = chosenCost
+			best = optimizer.TakeSnapshot(tt, buses, drvs)
+		}
+		optimizer.Notify(ctx, optimizer.Event{
+			Optimizer: "tabu",
+			Epoch:     it + 1,
+			Best:      bestEnergy,
+			Current:   chosenCost,
+			Drivers:   best.Drivers(),
+			Buses:     best.Buses(),
+			Elapsed:   time.Since(start),
+		})
+	}
+
+	best.Restore(tt, buses, drvs)
+	buses.AllocateDepots(tt)
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = iterations
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
+	}
+	return res, nil
+}
+
+// candidates выбирает до Candidates допустимых ходов.
+// Половина переносов берет путь у водителя с наименьшим числом путей,
+// чтобы такого водителя можно было уволить. Путь, нарушающий правила
+// своего водителя, может уйти новому водителю того же типа.
+func (t *tabu) candidates(s *localsearch.State, rnd *rand.Rand) []move {
+	paths := s.PathIDs(func(p path.Path) bool { return p.DriverID != uuid.Nil })
+	ids := s.DriverIDs()
+	if len(paths) == 0 || len(ids) < 2 {
+		return nil
+	}
+
+	load := make(map[uuid.UUID]int, len(ids))
+	for _, id := range paths {
+		load[s.TT.GetPathByID(id).DriverID]++
+	}
+	lightest := uuid.Nil
+	for _, id := range ids {
+		if load[id] > 0 && (lightest == uuid.Nil || load[id] < load[lightest]) {
+			lightest = id
+		}
+	}
+	lightPaths := s.PathIDs(func(p path.Path) bool { return p.DriverID == lightest })
+
+	res := make([]move, 0, t.cfg.Candidates)
+	for attempt := 0; attempt < t.cfg.Candidates*3 && len(res) < t.cfg.Candidates; attempt++ {
+		p := s.TT.GetPathByID(paths[rnd.IntN(len(paths))])
+		if len(lightPaths) > 0 && rnd.IntN(2) == 0 {
+			p = s.TT.GetPathByID(lightPaths[rnd.IntN(len(lightPaths))])
+		}
+		from := s.Drivers.GetDriver(p.DriverID)
+		to := s.Drivers.GetDriver(ids[rnd.IntN(len(ids))])
+		if from == nil || to == nil || to.ID() == from.ID() {
+			continue
+		}
+
+		// путь, который его водитель не может законно выполнить,
+		// можно отдать и новому водителю
+		if !s.CanTake(from, p, uuid.Nil) && rnd.IntN(2) == 0 {
+			to = driver.New(s.Drivers.NewID(), from.Type())
+		}
+
+		if rnd.IntN(2) == 0 {
+			if s.CanTake(to, p, uuid.Nil) {
+				res = append(res, move{trips: []uuid.UUID{p.ID}, to: []driver.Driver{to}})
+			}
+			continue
+		}
+
+		other := s.PathIDs(func(o path.Path) bool { return o.DriverID == to.ID() })
+		if len(other) == 0 {
+			continue
+		}
+		q := s.TT.GetPathByID(other[rnd.IntN(len(other))])
+		if s.CanTake(to, p, q.ID) && s.CanTake(from, q, p.ID) {
+			res = append(res, move{trips: []uuid.UUID{p.ID, q.ID}, to: []driver.Driver{to, from}})
+		}
+	}
+	return res
+}
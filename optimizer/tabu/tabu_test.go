@@ -0,0 +1,91 @@
+package tabu
+
+import (
+	"context"
+	"course/optimizer"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"maps"
+	"testing"
+	"time"
+)
+
+// perTrip - базовое решение: каждый путь ведет свой водитель типа A,
+// все пути идут на одном автобусе
+type perTrip struct{}
+
+func (perTrip) Optimize(
+	_ context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	b := bus.NewBus(uuid.UUID{0xb1})
+	buses.Register(b)
+	for id := range tt.GetEach(func(p path.Path) bool { return true }) {
+		d := driver.New(drvs.NewID(), "A")
+		drvs.Register(d)
+		tt.AssignDriverToPath(id, d.ID())
+		tt.AssignBusToPath(id, b.ID)
+	}
+	return optimizer.Summarize(tt, buses, drvs), nil
+}
+
+// четыре часовых пути через полчаса друг за другом помещаются в одну смену:
+// поиск убирает лишних водителей и не трогает автобусы
+func TestTabuImprovesDuties(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 4; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(6*time.Hour + time.Duration(i)*90*time.Minute)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+		}, nil)
+	}
+	tt := ttb.Build()
+	buses := station.NewBusStationBuilder().Build()
+	drvs := driverhub.NewDriverHubBuilder().Build()
+
+	base, _ := perTrip{}.Optimize(context.Background(), ttb.Build(), station.NewBusStationBuilder().Build(),
+		driverhub.NewDriverHubBuilder().Build())
+
+	cfg := DefaultConfig()
+	cfg.Seed = 1
+	res, err := New(perTrip{}, cfg).Optimize(context.Background(), tt, buses, drvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Objective >= base.Objective {
+		t.Errorf("Objective = %.2f, want below the base %.2f", res.Objective, base.Objective)
+	}
+	if res.Drivers >= base.Drivers {
+		t.Errorf("Drivers = %d, want fewer than %d", res.Drivers, base.Drivers)
+	}
+
+	busOf := make(map[uuid.UUID]uuid.UUID)
+	for id, p := range tt.GetEach(func(p path.Path) bool { return true }) {
+		busOf[id] = p.BusID
+	}
+	want := map[uuid.UUID]uuid.UUID{
+		{1}: {0xb1}, {2}: {0xb1}, {3}: {0xb1}, {4}: {0xb1},
+	}
+	if !maps.Equal(busOf, want) {
+		t.Errorf("buses changed: %v", busOf)
+	}
+}
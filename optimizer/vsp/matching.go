@@ -0,0 +1,67 @@
+package vsp
+
+// hopcroftKarp - максимальное паросочетание в двудольном графе,
+// где обе доли - пути 0..n-1, а ребро i→j значит, что j может идти за i.
+// Возвращает next: next[i] - путь, идущий в цепочке за i, или -1.
+func hopcroftKarp(adj [][]int, n int) []int {
+	const inf = int(^uint(0) >> 1)
+
+	matchL := make([]int, n)
+	matchR := make([]int, n)
+	for i := range matchL {
+		matchL[i] = -1
+		matchR[i] = -1
+	}
+	dist := make([]int, n)
+
+	bfs := func() bool {
+		queue := make([]int, 0, n)
+		for u := range matchL {
+			if matchL[u] < 0 {
+				dist[u] = 0
+				queue = append(queue, u)
+			} else {
+				dist[u] = inf
+			}
+		}
+		found := false
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, v := range adj[u] {
+				w := matchR[v]
+				if w < 0 {
+					found = true
+				} else if dist[w] == inf {
+					dist[w] = dist[u] + 1
+					queue = append(queue, w)
+				}
+			}
+		}
+		return found
+	}
+
+	var dfs func(u int) bool
+	dfs = func(u int) bool {
+		for _, v := range adj[u] {
+			w := matchR[v]
+			if w < 0 || (dist[w] == dist[u]+1 && dfs(w)) {
+				matchL[u] = v
+				matchR[v] = u
+				return true
+			}
+		}
+		dist[u] = inf
+		return false
+	}
+
+	for bfs() {
+		for u := range matchL {
+			if matchL[u] < 0 {
+				dfs(u)
+			}
+		}
+	}
+
+	return matchL
+}
@@ -0,0 +1,180 @@
+package vsp
+
+import (
+	"context"
+	"course/optimizer"
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+type vsp struct{}
+
+// New - точное решение задачи о минимальном парке.
+// Цепочки автобусов - минимальное покрытие путями графа совместимости рейсов,
+// найденное паросочетанием Хопкрофта-Карпа. Водители назначаются жадно
+// по правилам своего типа.
+func New() optimizer.Optimizer {
+	return &vsp{}
+}
+
+func (v *vsp) Optimize(
+	ctx context.Context,
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) (optimizer.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return optimizer.Result{Status: optimizer.StatusTimedOut}, err
+	}
+	start := time.Now()
+
+	blocks := Blocks(tt)
+	AssignBuses(tt, buses, blocks)
+	assignDrivers(tt, drvs)
+
+	res := optimizer.Summarize(tt, buses, drvs)
+	res.Iterations = 1
+	res.WallTime = time.Since(start)
+	if ctx.Err() != nil {
+		res.Status = optimizer.StatusTimedOut
+	}
+	return res, nil
+}
+
+// MinFleet - минимальное число автобусов, способных выполнить все пути расписания.
+// Это нижняя граница для любого оптимизатора.
+func MinFleet(tt timetable.Timetable) int {
+	return len(Blocks(tt))
+}
+
+// Blocks разбивает все пути расписания на минимальное число цепочек,
+// каждую из которых может выполнить один автобус. Пути в цепочке идут по времени.
+func Blocks(tt timetable.Timetable) [][]path.Path {
+	trips := tt.PathsByStart()
+	n := len(trips)
+
+	adj := make([][]int, n)
+	for i := range trips {
+		for j := i + 1; j < n; j++ {
+			if tt.Follows(trips[i], trips[j]) {
+				adj[i] = append(adj[i], j)
+			}
+		}
+	}
+
+	next := hopcroftKarp(adj, n)
+
+	hasPrev := make([]bool, n)
+	for _, j := range next {
+		if j >= 0 {
+			hasPrev[j] = true
+		}
+	}
+
+	var blocks [][]path.Path
+	for i := range trips {
+		if hasPrev[i] {
+			continue
+		}
+		var block []path.Path
+		for k := i; k >= 0; k = next[k] {
+			block = append(block, trips[k])
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// AssignBuses отдает каждую цепочку своему автобусу: сначала автобусам парка,
+// затем новым. Автобусы, оставшиеся без цепочки, списываются,
+// а каждая цепочка уходит в ближайшее к ее концам депо.
+func AssignBuses(tt timetable.Timetable, buses *station.BusStation, blocks [][]path.Path) {
+	ids := make([]uuid.UUID, 0)
+	for id := range buses.Buses() {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	for i, block := range blocks {
+		var id uuid.UUID
+		if i < len(ids) {
+			id = ids[i]
+		} else {
+			b := bus.NewBus(buses.NewID())
+			buses.Register(b)
+			id = b.ID
+		}
+		for _, p := range block {
+			tt.AssignBusToPath(p.ID, id)
+		}
+	}
+	for i := len(blocks); i < len(ids); i++ {
+		buses.Unregister(ids[i])
+	}
+	buses.AllocateDepots(tt)
+}
+
+// assignDrivers назначает пути по времени начала тому водителю, который может
+// взять путь по правилам своего типа и освободился позже всех; если такого нет,
+// нанимает водителя типа B: его смена покрывает весь рабочий день.
+// Водители, оставшиеся без путей, увольняются.
+func assignDrivers(tt timetable.Timetable, drvs *driverhub.DriverHub) {
+	type duty struct {
+		d  driver.Driver
+		ps []path.Path
+	}
+
+	var duties []*duty
+	for _, d := range drvs.Drivers() {
+		duties = append(duties, &duty{d: d})
+	}
+	slices.SortFunc(duties, func(a, b *duty) int { return strings.Compare(a.d.ID().String(), b.d.ID().String()) })
+
+	for _, p := range tt.PathsByStart() {
+		var best *duty
+		for _, dt := range duties {
+			if !canAppend(dt.d, dt.ps, p) {
+				continue
+			}
+			if best == nil || lastEnd(dt.ps).After(lastEnd(best.ps)) {
+				best = dt
+			}
+		}
+		if best == nil {
+			best = &duty{d: driver.New(drvs.NewID(), driver.Longest().Name)}
+			drvs.Register(best.d)
+			duties = append(duties, best)
+		}
+		best.ps = append(best.ps, p)
+		tt.AssignDriverToPath(p.ID, best.d.ID())
+	}
+
+	for _, dt := range duties {
+		if len(dt.ps) == 0 {
+			drvs.Unregister(dt.d.ID())
+		}
+	}
+}
+
+func lastEnd(ps []path.Path) time.Time {
+	if len(ps) == 0 {
+		return time.Time{}
+	}
+	return ps[len(ps)-1].EndTime
+}
+
+// canAppend - можно ли дописать путь p в конец смены ps водителя d
+func canAppend(d driver.Driver, ps []path.Path, p path.Path) bool {
+	if len(ps) > 0 && p.StartTime.Before(lastEnd(ps)) {
+		return false
+	}
+	return driver.NewShift(d, append(slices.Clone(ps), p)).Legal()
+}
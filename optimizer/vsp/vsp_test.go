@@ -0,0 +1,123 @@
+package vsp
+
+import (
+	"course/pkg/path"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHopcroftKarp(t *testing.T) {
+	tests := []struct {
+		name string
+		adj  [][]int
+		// размер наибольшего паросочетания
+		want int
+	}{
+		{"empty", [][]int{}, 0},
+		{"no edges", [][]int{{}, {}, {}}, 0},
+		{"chain", [][]int{{1}, {2}, {}}, 2},
+		{"one successor for two", [][]int{{2}, {2}, {}}, 1},
+		{"augmenting path", [][]int{{2, 3}, {2}, {}, {}}, 2},
+		{"complete order", [][]int{{1, 2, 3}, {2, 3}, {3}, {}}, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next := hopcroftKarp(tc.adj, len(tc.adj))
+			size := 0
+			used := make(map[int]bool)
+			for i, j := range next {
+				if j < 0 {
+					continue
+				}
+				if !slices.Contains(tc.adj[i], j) {
+					t.Fatalf("next[%d] = %d is not an edge", i, j)
+				}
+				if used[j] {
+					t.Fatalf("%d follows two paths", j)
+				}
+				used[j] = true
+				size++
+			}
+			if size != tc.want {
+				t.Errorf("matching size = %d, want %d", size, tc.want)
+			}
+		})
+	}
+}
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// point - конечная с именем name
+func point(name byte) path.Point {
+	return path.Point{Id: uuid.UUID{name}, Name: string(name), IsBusStation: true}
+}
+
+// trip - путь номер n из from в to; start и end - минуты от полуночи
+type trip struct {
+	n          int
+	from, to   byte
+	start, end int
+}
+
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		trips []trip
+		// перегон из B в C; 0 - точки не связаны
+		bc time.Duration
+		// номера путей каждого блока
+		want [][]int
+	}{
+		{"empty", nil, 0, nil},
+		{"chain", []trip{{1, 'A', 'B', 360, 420}, {2, 'B', 'A', 450, 510}, {3, 'A', 'B', 540, 600}}, 0,
+			[][]int{{1, 2, 3}}},
+		{"overlapping trips", []trip{{1, 'A', 'B', 360, 480}, {2, 'A', 'B', 420, 540}, {3, 'A', 'B', 450, 570}}, 0,
+			[][]int{{1}, {2}, {3}}},
+		{"deadhead in time", []trip{{1, 'A', 'B', 360, 420}, {2, 'C', 'D', 450, 510}}, 30 * time.Minute,
+			[][]int{{1, 2}}},
+		{"deadhead too long", []trip{{1, 'A', 'B', 360, 420}, {2, 'C', 'D', 440, 510}}, 30 * time.Minute,
+			[][]int{{1}, {2}}},
+		{"no deadhead", []trip{{1, 'A', 'B', 360, 420}, {2, 'C', 'D', 600, 660}}, 0,
+			[][]int{{1}, {2}}},
+		{"two chains", []trip{{1, 'A', 'B', 360, 420}, {2, 'A', 'B', 380, 440}, {3, 'B', 'A', 430, 490},
+			{4, 'B', 'A', 450, 510}}, 0,
+			[][]int{{1, 3}, {2, 4}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ttb := ttv1.NewBuilder()
+			for i, tr := range tc.trips {
+				var dst []path.DstItem
+				if i == 0 && tc.bc > 0 {
+					dst = []path.DstItem{{From: point('B').Id, To: point('C').Id, Dur: tc.bc}}
+				}
+				ttb.AddPath(path.Path{
+					ID:        uuid.UUID{byte(tr.n)},
+					Number:    tr.n,
+					Points:    []path.Point{point(tr.from), point(tr.to)},
+					StartTime: day.Add(time.Duration(tr.start) * time.Minute),
+					EndTime:   day.Add(time.Duration(tr.end) * time.Minute),
+				}, dst)
+			}
+			tt := ttb.Build()
+
+			var got [][]int
+			for _, blk := range Blocks(tt) {
+				var ns []int
+				for _, p := range blk {
+					ns = append(ns, p.Number)
+				}
+				got = append(got, ns)
+			}
+			if !slices.EqualFunc(got, tc.want, slices.Equal[[]int]) {
+				t.Errorf("Blocks = %v, want %v", got, tc.want)
+			}
+			if n := MinFleet(tt); n != len(tc.want) {
+				t.Errorf("MinFleet = %d, want %d", n, len(tc.want))
+			}
+		})
+	}
+}
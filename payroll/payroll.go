@@ -0,0 +1,261 @@
+package payroll
+
+import (
+	"course/config"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"encoding/csv"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config - правила оплаты труда
+type Config struct {
+	// во сколько раз час сверх WorkDur дороже обычного
+	OvertimeMultiplier float64
+	// надбавка за ночной час, доля часовой ставки
+	NightPremium float64
+	// ночь - с NightStart до NightEnd следующего дня, часы суток
+	NightStart int
+	NightEnd   int
+	// сколько часов оплачивается за смену, даже если она короче
+	MinGuaranteed time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		OvertimeMultiplier: 1.5,
+		NightPremium:       0.2,
+		NightStart:         22,
+		NightEnd:           6,
+		MinGuaranteed:      4 * time.Hour,
+	}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.PayrollConfig) Config {
+	cfg := DefaultConfig()
+	if c.OvertimeMultiplier > 0 {
+		cfg.OvertimeMultiplier = c.OvertimeMultiplier
+	}
+	if c.NightPremium > 0 {
+		cfg.NightPremium = c.NightPremium
+	}
+	if c.NightStartHour != nil {
+		cfg.NightStart = *c.NightStartHour
+	}
+	if c.NightEndHour != nil {
+		cfg.NightEnd = *c.NightEndHour
+	}
+	if c.MinGuaranteedMin > 0 {
+		cfg.MinGuaranteed = time.Duration(c.MinGuaranteedMin) * time.Minute
+	}
+	return cfg
+}
+
+// Line - расчетный лист одного водителя за смену
+type Line struct {
+	DriverID uuid.UUID
+	Type     driver.DriverType
+	Paths    int
+	// от начала первого пути до конца последнего
+	Spread time.Duration
+	// положенные перерывы: не больше RestCount по RestDur, не оплачиваются
+	UnpaidBreaks time.Duration
+	// оплачиваемое время: Spread без неоплачиваемых перерывов
+	Paid time.Duration
+	// оплачиваемое время сверх WorkDur
+	Overtime time.Duration
+	// оплачиваемое время в ночные часы
+	Night time.Duration
+
+	RegularPay  float64
+	OvertimePay float64
+	NightPay    float64
+	// доплата до гарантированного минимума смены
+	GuaranteePay float64
+}
+
+func (l Line) Total() float64 {
+	return l.RegularPay + l.OvertimePay + l.NightPay + l.GuaranteePay
+}
+
+func (l *Line) add(o Line) {
+	l.Paths += o.Paths
+	l.Spread += o.Spread
+	l.UnpaidBreaks += o.UnpaidBreaks
+	l.Paid += o.Paid
+	l.Overtime += o.Overtime
+	l.Night += o.Night
+	l.RegularPay += o.RegularPay
+	l.OvertimePay += o.OvertimePay
+	l.NightPay += o.NightPay
+	l.GuaranteePay += o.GuaranteePay
+}
+
+// Payroll - ведомость по всем водителям расписания
+type Payroll struct {
+	// по водителю, в порядке идентификаторов
+	Lines []Line
+	// сумма по всем водителям
+	Total Line
+}
+
+// Compute считает ведомость по назначенным путям расписания.
+// Водитель без путей получает гарантированный минимум смены.
+func Compute(cfg Config, tt timetable.Timetable, dh *driverhub.DriverHub) Payroll {
+	byDriver := make(map[uuid.UUID][]path.Path)
+	for _, p := range tt.GetEach(func(p path.Path) bool { return p.DriverID != uuid.Nil }) {
+		byDriver[p.DriverID] = append(byDriver[p.DriverID], p)
+	}
+
+	drivers := dh.Drivers()
+	ids := make([]uuid.UUID, 0, len(drivers))
+	for id := range drivers {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	var pr Payroll
+	for _, id := range ids {
+		l := Driver(cfg, drivers[id], byDriver[id])
+		pr.Lines = append(pr.Lines, l)
+		pr.Total.add(l)
+	}
+	return pr
+}
+
+// Driver - расчетный лист водителя d с путями ps
+func Driver(cfg Config, d driver.Driver, ps []path.Path) Line {
+	spec := d.Spec()
+	shift := driver.NewShift(d, ps)
+	l := Line{DriverID: d.ID(), Type: spec.Name, Paths: len(ps), Spread: shift.Spread()}
+
+	for _, iv := range paidIntervals(shift.Paths(), spec) {
+		l.Paid += iv.end.Sub(iv.start)
+		l.Night += nightOverlap(iv.start, iv.end, cfg.NightStart, cfg.NightEnd)
+	}
+	l.UnpaidBreaks = l.Spread - l.Paid
+	l.Overtime = max(l.Paid-spec.WorkDur, 0)
+
+	rate := spec.HourlyCost
+	l.RegularPay = (l.Paid - l.Overtime).Hours() * rate
+	l.OvertimePay = l.Overtime.Hours() * rate * cfg.OvertimeMultiplier
+	l.NightPay = l.Night.Hours() * rate * cfg.NightPremium
+	if guaranteed := cfg.MinGuaranteed.Hours() * rate; l.RegularPay+l.OvertimePay < guaranteed {
+		l.GuaranteePay = guaranteed - l.RegularPay - l.OvertimePay
+	}
+	return l
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+// paidIntervals - оплачиваемые отрезки смены: пути и промежутки между ними,
+// кроме первых RestDur каждого из первых RestCount перерывов
+func paidIntervals(ps []path.Path, spec driver.Spec) []interval {
+	var (
+		res    []interval
+		breaks int64
+	)
+	for i, p := range ps {
+		if i > 0 {
+			from := ps[i-1].EndTime
+			gap := p.StartTime.Sub(from)
+			if gap >= spec.RestDur && breaks < spec.RestCount {
+				breaks++
+				from = from.Add(spec.RestDur)
+			}
+			if p.StartTime.After(from) {
+				res = append(res, interval{from, p.StartTime})
+			}
+		}
+		res = append(res, interval{p.StartTime, p.EndTime})
+	}
+	return res
+}
+
+// nightOverlap - сколько времени отрезка [start, end) приходится на ночь
+// с часа from до часа to (следующих суток, если to <= from)
+func nightOverlap(start, end time.Time, from, to int) time.Duration {
+	var total time.Duration
+	y, m, d := start.Date()
+	for day := time.Date(y, m, d-1, 0, 0, 0, 0, start.Location()); day.Before(end); day = day.AddDate(0, 0, 1) {
+		ns := day.Add(time.Duration(from) * time.Hour)
+		ne := day.Add(time.Duration(to) * time.Hour)
+		if to <= from {
+			ne = ne.AddDate(0, 0, 1)
+		}
+		s, e := maxTime(start, ns), minTime(end, ne)
+		if e.After(s) {
+			total += e.Sub(s)
+		}
+	}
+	return total
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Save сохраняет ведомость в filename.csv: строка на водителя и итоговая строка
+func (pr Payroll) Save(filename string) error {
+	file, err := os.Create(fmt.Sprintf("%s.csv", filename))
+	if err != nil {
+		return fmt.Errorf("Ошибка создания файла: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	_ = w.Write([]string{
+		"driver", "type", "paths", "spread_h", "unpaid_breaks_h", "paid_h", "overtime_h", "night_h",
+		"regular_pay", "overtime_pay", "night_pay", "guarantee_pay", "total_pay",
+	})
+	for _, l := range pr.Lines {
+		_ = w.Write(record(l.DriverID.String(), string(l.Type), l))
+	}
+	_ = w.Write(record("total", "", pr.Total))
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Ошибка записи в файл: %w", err)
+	}
+	return nil
+}
+
+func record(name, typ string, l Line) []string {
+	hours := func(d time.Duration) string { return strconv.FormatFloat(d.Hours(), 'f', 2, 64) }
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	return []string{
+		name,
+		typ,
+		strconv.Itoa(l.Paths),
+		hours(l.Spread),
+		hours(l.UnpaidBreaks),
+		hours(l.Paid),
+		hours(l.Overtime),
+		hours(l.Night),
+		money(l.RegularPay),
+		money(l.OvertimePay),
+		money(l.NightPay),
+		money(l.GuaranteePay),
+		money(l.Total()),
+	}
+}
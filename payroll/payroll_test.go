@@ -0,0 +1,99 @@
+package payroll
+
+import (
+	"course/config"
+	"course/pkg/driver"
+	"course/pkg/path"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// at - момент "15:04" дня day; часы сверх 23 переходят на следующий день
+func at(hhmm string) time.Time {
+	var h, m int
+	if _, err := fmt.Sscanf(hhmm, "%d:%d", &h, &m); err != nil {
+		panic(err)
+	}
+	return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+func TestPaidIntervals(t *testing.T) {
+	spec := driver.Spec{RestDur: time.Hour, RestCount: 1}
+	trip := func(from, to string) path.Path { return path.Path{StartTime: at(from), EndTime: at(to)} }
+	tests := []struct {
+		name string
+		ps   []path.Path
+		// пары начало-конец оплачиваемых отрезков
+		want []string
+	}{
+		{"one path", []path.Path{trip("06:00", "08:00")}, []string{"06:00", "08:00"}},
+		{"short gap is paid", []path.Path{trip("06:00", "08:00"), trip("08:30", "10:00")},
+			[]string{"06:00", "08:00", "08:00", "08:30", "08:30", "10:00"}},
+		{"rest is not paid", []path.Path{trip("06:00", "08:00"), trip("09:00", "10:00")},
+			[]string{"06:00", "08:00", "09:00", "10:00"}},
+		{"only RestDur of a long gap is unpaid", []path.Path{trip("06:00", "08:00"), trip("09:30", "10:00")},
+			[]string{"06:00", "08:00", "09:00", "09:30", "09:30", "10:00"}},
+		{"breaks over RestCount are paid", []path.Path{trip("06:00", "07:00"), trip("08:00", "09:00"), trip("10:00", "11:00")},
+			[]string{"06:00", "07:00", "08:00", "09:00", "09:00", "10:00", "10:00", "11:00"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, iv := range paidIntervals(tc.ps, spec) {
+				got = append(got, iv.start.Format("15:04"), iv.end.Format("15:04"))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("paidIntervals = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNightOverlap(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		from, to   int
+		want       time.Duration
+	}{
+		{"day only", "06:00", "22:00", 22, 6, 0},
+		{"evening", "20:00", "23:00", 22, 6, time.Hour},
+		{"across midnight", "21:00", "27:00", 22, 6, 5 * time.Hour},
+		{"early morning", "04:00", "08:00", 22, 6, 2 * time.Hour},
+		{"whole day", "00:00", "24:00", 22, 6, 8 * time.Hour},
+		{"night from midnight", "23:00", "26:00", 0, 5, 2 * time.Hour},
+		{"night until midnight", "22:00", "25:00", 21, 0, 2 * time.Hour},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nightOverlap(at(tc.start), at(tc.end), tc.from, tc.to); got != tc.want {
+				t.Errorf("nightOverlap = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromConfigNightHours(t *testing.T) {
+	hour := func(h int) *int { return &h }
+	tests := []struct {
+		name       string
+		c          config.PayrollConfig
+		start, end int
+	}{
+		{"unset", config.PayrollConfig{}, DefaultConfig().NightStart, DefaultConfig().NightEnd},
+		{"midnight", config.PayrollConfig{NightStartHour: hour(0), NightEndHour: hour(0)}, 0, 0},
+		{"custom", config.PayrollConfig{NightStartHour: hour(23), NightEndHour: hour(5)}, 23, 5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := FromConfig(tc.c)
+			if cfg.NightStart != tc.start || cfg.NightEnd != tc.end {
+				t.Errorf("night = %d-%d, want %d-%d", cfg.NightStart, cfg.NightEnd, tc.start, tc.end)
+			}
+		})
+	}
+}
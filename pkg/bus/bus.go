@@ -6,6 +6,9 @@ import (
 
 type Bus struct {
 	ID uuid.UUID
+	// точка-депо, из которой автобус выходит на линию и куда возвращается;
+	// uuid.Nil - депо станции, в которой автобус зарегистрирован
+	Depot uuid.UUID
 }
 
 func NewBus(id uuid.UUID) *Bus {
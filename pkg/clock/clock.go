@@ -64,10 +64,7 @@ func (c *Clock) Start(ctx context.Context) {
 }
 
 func (c *Clock) Now() time.Time {
-	for !c.mu.TryLock() {
-		res := c.currentTime
-		c.mu.Unlock()
-		return res
-	}
-	return time.Time{}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentTime
 }
@@ -0,0 +1,168 @@
+package depot
+
+import (
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/random"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Depot - конечная, при которой стоит свой парк автобусов и свой штат водителей
+type Depot struct {
+	Point   path.Point
+	Buses   *station.BusStation
+	Drivers *driverhub.DriverHub
+}
+
+// Builder собирает депо сцены. Оптимизаторы работают с общим парком
+// и штатом всех депо из Pool, а по готовому расписанию Split снова
+// раскладывает автобусы и водителей по депо.
+type Builder struct {
+	mu      sync.Mutex
+	points  []path.Point
+	buses   []*station.BusStationBuilder
+	drivers []*driverhub.DriverHubBuilder
+	// зерно идентификаторов общего парка и штата из Pool
+	seed uint64
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Add добавляет депо в точке point с парком buses и штатом drivers.
+// Билдеры привязываются к point.
+func (b *Builder) Add(
+	point path.Point,
+	buses *station.BusStationBuilder,
+	drivers *driverhub.DriverHubBuilder,
+) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	buses.SetDepot(point)
+	drivers.SetStation(point.Id)
+	b.points = append(b.points, point)
+	b.buses = append(b.buses, buses)
+	b.drivers = append(b.drivers, drivers)
+}
+
+// SetSeed задает зерно идентификаторов новых автобусов и водителей: общего
+// парка и штата из Pool и каждого добавленного депо
+func (b *Builder) SetSeed(seed uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seed = seed
+	for i, p := range b.points {
+		b.buses[i].SetSeed(random.Derive(seed, p.Id.String()))
+		b.drivers[i].SetSeed(random.Derive(seed, p.Id.String()))
+	}
+}
+
+// Build - депо по отдельности, в порядке добавления
+func (b *Builder) Build() []Depot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	depots := make([]Depot, 0, len(b.points))
+	for i, p := range b.points {
+		depots = append(depots, Depot{
+			Point:   p,
+			Buses:   b.buses[i].Build(),
+			Drivers: b.drivers[i].Build(),
+		})
+	}
+	return depots
+}
+
+// Pool - общий парк и штат всех депо. Автобус помнит свое депо в bus.Depot,
+// а парк знает все депо сцены, так что оптимизатор может перевести блок
+// в другое депо через AllocateDepots. Новые автобусы приписываются
+// к первому депо.
+func (b *Builder) Pool() (*driverhub.DriverHubBuilder, *station.BusStationBuilder) {
+	dhb := driverhub.NewDriverHubBuilder()
+	bsb := station.NewBusStationBuilder()
+	b.mu.Lock()
+	seed := b.seed
+	b.mu.Unlock()
+	dhb.SetSeed(seed)
+	bsb.SetSeed(seed)
+	for _, d := range b.Build() {
+		bsb.AddDepot(d.Point)
+		for _, bs := range d.Buses.Buses() {
+			bsb.AddBus(&bs)
+		}
+		for _, drv := range d.Drivers.Drivers() {
+			dhb.AddDriver(drv)
+		}
+	}
+	return dhb, bsb
+}
+
+// Split раскладывает общий парк buses и штат drvs по депо сцены.
+// Автобус уходит в свое депо. Водитель остается в штате своего депо,
+// а нанятый оптимизатором - в депо автобуса своего первого пути.
+func (b *Builder) Split(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+) []Depot {
+	depots := b.Build()
+	if len(depots) == 0 {
+		return nil
+	}
+
+	home := make(map[uuid.UUID]int)
+	byPoint := make(map[uuid.UUID]int)
+	for i, d := range depots {
+		byPoint[d.Point.Id] = i
+		for id := range d.Drivers.Drivers() {
+			home[id] = i
+			d.Drivers.Unregister(id)
+		}
+		for id := range d.Buses.Buses() {
+			d.Buses.Unregister(id)
+		}
+	}
+
+	for _, bs := range buses.Buses() {
+		depots[byPoint[bs.Depot]].Buses.Register(&bs)
+	}
+
+	for _, id := range sortedDrivers(drvs) {
+		d := drvs.GetDriver(id)
+		i, ok := home[id]
+		if !ok {
+			i = byPoint[firstBusDepot(tt, buses, id)]
+		}
+		depots[i].Drivers.Register(d)
+	}
+	return depots
+}
+
+// firstBusDepot - депо автобуса, на котором водитель начинает смену;
+// uuid.Nil, если путей у водителя нет
+func firstBusDepot(tt timetable.Timetable, buses *station.BusStation, driverID uuid.UUID) uuid.UUID {
+	ps := tt.DriverPaths(driverID)
+	if len(ps) == 0 {
+		return uuid.Nil
+	}
+	b := buses.GetBus(ps[0].BusID)
+	if b == nil {
+		return uuid.Nil
+	}
+	return b.Depot
+}
+
+func sortedDrivers(drvs *driverhub.DriverHub) []uuid.UUID {
+	all := drvs.Drivers()
+	ids := make([]uuid.UUID, 0, len(all))
+	for id := range all {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
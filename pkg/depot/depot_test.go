@@ -0,0 +1,117 @@
+package depot
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func point(name byte) path.Point {
+	return path.Point{Id: uuid.UUID{name}, Name: string(name), IsBusStation: true}
+}
+
+// scene - депо A с автобусом b1 и водителем d1, депо B с автобусом b2 и водителем d2
+func scene() *Builder {
+	b := NewBuilder()
+	for i, name := range []byte{'A', 'B'} {
+		bsb := station.NewBusStationBuilder()
+		bsb.AddBus(bus.NewBus(uuid.UUID{0xb1 + byte(i)}))
+		dhb := driverhub.NewDriverHubBuilder()
+		dhb.AddDriver(driver.New(uuid.UUID{0xd1 + byte(i)}, "A"))
+		b.Add(point(name), bsb, dhb)
+	}
+	b.SetSeed(1)
+	return b
+}
+
+func ids[V any](m map[uuid.UUID]V) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(m))
+	for id := range m {
+		res = append(res, id)
+	}
+	slices.SortFunc(res, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return res
+}
+
+// блок, который начинается и кончается у депо B, уходит в B вместе
+// с нанятым на него водителем, а водители сцены остаются в своих депо
+func TestPoolAllocateSplit(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	ttb := ttv1.NewBuilder()
+	// из A к B и обратно - по 30 минут, от C к B - 20
+	ttb.AddPath(path.Path{
+		ID:        uuid.UUID{1},
+		Number:    1,
+		Points:    []path.Point{point('B'), point('C')},
+		StartTime: day.Add(6 * time.Hour),
+		EndTime:   day.Add(7 * time.Hour),
+	}, []path.DstItem{
+		{From: point('A').Id, To: point('B').Id, Dur: 30 * time.Minute},
+		{From: point('B').Id, To: point('A').Id, Dur: 30 * time.Minute},
+		{From: point('C').Id, To: point('B').Id, Dur: 20 * time.Minute},
+	})
+	tt := ttb.Build()
+
+	b := scene()
+	dhb, bsb := b.Pool()
+	drvs, buses := dhb.Build(), bsb.Build()
+	if got := buses.Depots(); !slices.Equal(got, []path.Point{point('A'), point('B')}) {
+		t.Fatalf("Depots = %v, want A and B", got)
+	}
+	if d := buses.GetBus(uuid.UUID{0xb1}).Depot; d != point('A').Id {
+		t.Fatalf("b1 depot = %v, want A before allocation", d)
+	}
+
+	hired := driver.New(drvs.NewID(), "A")
+	drvs.Register(hired)
+	tt.AssignDriverToPath(uuid.UUID{1}, hired.ID())
+	tt.AssignBusToPath(uuid.UUID{1}, uuid.UUID{0xb1})
+
+	buses.AllocateDepots(tt)
+	if d := buses.GetBus(uuid.UUID{0xb1}).Depot; d != point('B').Id {
+		t.Errorf("b1 depot = %v, want B", d)
+	}
+
+	depots := b.Split(tt, buses, drvs)
+	if len(depots) != 2 {
+		t.Fatalf("Split gave %d depots, want 2", len(depots))
+	}
+	if got := ids(depots[0].Buses.Buses()); len(got) != 0 {
+		t.Errorf("depot A buses = %v, want none", got)
+	}
+	if got, want := ids(depots[1].Buses.Buses()), []uuid.UUID{{0xb1}, {0xb2}}; !slices.Equal(got, want) {
+		t.Errorf("depot B buses = %v, want %v", got, want)
+	}
+	if got, want := ids(depots[0].Drivers.Drivers()), []uuid.UUID{{0xd1}}; !slices.Equal(got, want) {
+		t.Errorf("depot A drivers = %v, want %v", got, want)
+	}
+	wantB := map[uuid.UUID]bool{{0xd2}: true, hired.ID(): true}
+	gotB := make(map[uuid.UUID]bool)
+	for id := range depots[1].Drivers.Drivers() {
+		gotB[id] = true
+	}
+	if !maps.Equal(gotB, wantB) {
+		t.Errorf("depot B drivers = %v, want d2 and the hired driver", ids(gotB))
+	}
+}
+
+// одно зерно дает общему парку и штату одни и те же новые идентификаторы
+func TestPoolRepeatsBySeed(t *testing.T) {
+	dhb1, bsb1 := scene().Pool()
+	dhb2, bsb2 := scene().Pool()
+	if dhb1.Build().NewID() != dhb2.Build().NewID() {
+		t.Error("hired drivers get different IDs")
+	}
+	if bsb1.Build().NewID() != bsb2.Build().NewID() {
+		t.Error("new buses get different IDs")
+	}
+}
@@ -2,25 +2,25 @@ package driver
 
 import (
 	"course/pkg/path"
+	"fmt"
 	"github.com/google/uuid"
-	"slices"
 	"time"
 )
 
 type Driver interface {
 	ID() uuid.UUID
 	Type() DriverType
+	// Spec - правила и оплата типа водителя
+	Spec() Spec
 	NeedsRest(ps []path.Path) bool
 	RestDur() time.Duration
 	WorkDur() time.Duration
+	RestCount() int64
+	ContinuousDur() time.Duration
 }
 
-type DriverType = int
-
-const (
-	DriverA = iota
-	DriverB
-)
+// DriverType - имя типа водителя из конфигурации
+type DriverType = string
 
 type driver struct {
 	id          uuid.UUID
@@ -28,66 +28,38 @@ type driver struct {
 	timeEnd     time.Time
 	restTimeEnd time.Time
 
-	sets driverSets
-}
-
-type driverSets struct {
-	restTimeDur  time.Duration
-	workTimeDur  time.Duration
-	restCount    int64
-	workTimeDays int
-	weekendDays  int
-	typ          DriverType
-}
-
-func NewDriverA() Driver {
-	return newDriver(driverSets{
-		restTimeDur:  time.Hour,
-		workTimeDur:  time.Hour * 8,
-		restCount:    1,
-		workTimeDays: 5,
-		weekendDays:  2,
-		typ:          DriverA,
-	})
+	sets Spec
 }
 
-func NewDriverB() Driver {
-	return newDriver(driverSets{
-		restTimeDur:  20 * time.Minute,
-		workTimeDur:  18 * time.Hour,
-		restCount:    12,
-		workTimeDays: 5,
-		weekendDays:  2,
-		typ:          DriverB,
-	})
+// New создает водителя типа typ с идентификатором id. Тип должен быть среди Types.
+func New(id uuid.UUID, typ DriverType) Driver {
+	spec, ok := Lookup(typ)
+	if !ok {
+		panic(fmt.Sprintf("driver: unknown type %q", typ))
+	}
+	return newDriver(id, spec)
 }
 
 func (d *driver) ID() uuid.UUID { return d.id }
 
-func newDriver(sets driverSets) Driver { return &driver{id: uuid.New(), sets: sets} }
+func newDriver(id uuid.UUID, sets Spec) Driver { return &driver{id: id, sets: sets} }
+
+func (d *driver) Type() DriverType { return d.sets.Name }
 
-func (d *driver) Type() DriverType { return d.sets.typ }
+func (d *driver) Spec() Spec { return d.sets }
 
+// NeedsRest - нарушает ли водитель хотя бы одно правило своего типа,
+// если отработает пути ps: тогда ему нужен отдых, а не новый путь
 func (d *driver) NeedsRest(ps []path.Path) bool {
-	slices.SortFunc(ps, func(a, b path.Path) int {
-		if a.StartTime.Before(b.StartTime) {
-			return 1
-		}
-		return -1
-	})
-	var timeInWork time.Duration
-	for _, p := range ps {
-		// time in drive
-		timeInWork += p.EndTime.Sub(p.StartTime)
-		if timeInWork > d.sets.workTimeDur {
-			return true
-		}
-	}
-	return false
+	return !d.sets.Legal(ps)
 }
 
 func (d *driver) RestDur() time.Duration {
-	return d.sets.restTimeDur
+	return d.sets.RestDur
 }
 
-func (d *driver) WorkDur() time.Duration { return d.sets.workTimeDur }
+func (d *driver) WorkDur() time.Duration { return d.sets.WorkDur }
+
+func (d *driver) RestCount() int64 { return d.sets.RestCount }
+
+func (d *driver) ContinuousDur() time.Duration { return d.sets.ContinuousDur }
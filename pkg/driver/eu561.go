@@ -0,0 +1,166 @@
+package driver
+
+import (
+	"course/pkg/path"
+	"time"
+)
+
+// Профиль EU561 - ограничения времени вождения в духе регламента ЕС 561/2006:
+// перерыв 45 минут после 4,5 часа вождения (можно разбить на 15+30),
+// 9 часов вождения за смену, дважды в неделю до 10, ежедневный отдых 11 часов,
+// 56 часов в неделю и 90 часов за две недели подряд.
+const (
+	EUDrivingBeforeBreak = 4*time.Hour + 30*time.Minute
+	EUBreak              = 45 * time.Minute
+	EUSplitBreakFirst    = 15 * time.Minute
+	EUSplitBreakSecond   = 30 * time.Minute
+	EUDailyDriving       = 9 * time.Hour
+	EUExtendedDaily      = 10 * time.Hour
+	EUExtensionsPerWeek  = 2
+	EUDailyRest          = 11 * time.Hour
+	EUWeeklyDriving      = 56 * time.Hour
+	EUFortnightDriving   = 90 * time.Hour
+)
+
+// EU561 - правила профиля EU561
+func EU561() []Rule {
+	return []Rule{
+		NoOverlap{},
+		EUBreakRule{},
+		EUDailyDrivingRule{},
+		MinDailyRest{Dur: EUDailyRest},
+		EUWeeklyDrivingRule{},
+		EUFortnightDrivingRule{},
+	}
+}
+
+// Profile - правила встроенного профиля по имени
+func Profile(name string) ([]Rule, bool) {
+	switch name {
+	case "eu561":
+		return EU561(), true
+	default:
+		return nil, false
+	}
+}
+
+// EUBreakRule - не больше 4,5 часа вождения без перерыва 45 минут.
+// Перерыв можно разбить на 15 минут и следующие за ними 30.
+type EUBreakRule struct{}
+
+func (EUBreakRule) Name() string { return "eu_break" }
+
+func (r EUBreakRule) Check(ps []path.Path) Verdict {
+	for _, shift := range Shifts(ps) {
+		var (
+			driving time.Duration
+			// первая часть разбитого перерыва уже взята
+			split bool
+		)
+		for i, p := range shift {
+			if i > 0 {
+				gap := p.StartTime.Sub(shift[i-1].EndTime)
+				switch {
+				case gap >= EUBreak, split && gap >= EUSplitBreakSecond:
+					driving, split = 0, false
+				case gap >= EUSplitBreakFirst:
+					split = true
+				}
+			}
+			driving += p.EndTime.Sub(p.StartTime)
+			if driving > EUDrivingBeforeBreak {
+				return fail(r, "%s of driving before path %d at %s without a %s break",
+					driving, p.Number, p.StartTime.Format("02.01 15:04"), EUBreak)
+			}
+		}
+	}
+	return pass(r)
+}
+
+// EUDailyDrivingRule - не больше 9 часов вождения за смену между ежедневными
+// отдыхами, не больше двух смен в неделю до 10 часов
+type EUDailyDrivingRule struct{}
+
+func (EUDailyDrivingRule) Name() string { return "eu_daily_driving" }
+
+func (r EUDailyDrivingRule) Check(ps []path.Path) Verdict {
+	extended := make(map[time.Time]int)
+	for _, shift := range Shifts(ps) {
+		driving := drivingOf(shift)
+		start := shift[0].StartTime
+		switch {
+		case driving > EUExtendedDaily:
+			return fail(r, "%s of driving on %s, limit is %s", driving, start.Format("02.01"), EUExtendedDaily)
+		case driving > EUDailyDriving:
+			week := weekStart(start)
+			extended[week]++
+			if extended[week] > EUExtensionsPerWeek {
+				return fail(r, "%d days over %s in the week of %s, limit is %d",
+					extended[week], EUDailyDriving, week.Format("02.01"), EUExtensionsPerWeek)
+			}
+		}
+	}
+	return pass(r)
+}
+
+// EUWeeklyDrivingRule - не больше 56 часов вождения за календарную неделю
+type EUWeeklyDrivingRule struct{}
+
+func (EUWeeklyDrivingRule) Name() string { return "eu_weekly_driving" }
+
+func (r EUWeeklyDrivingRule) Check(ps []path.Path) Verdict {
+	weeks, order := weeklyDriving(ps)
+	for _, w := range order {
+		if weeks[w] > EUWeeklyDriving {
+			return fail(r, "%s of driving in the week of %s, limit is %s", weeks[w], w.Format("02.01"), EUWeeklyDriving)
+		}
+	}
+	return pass(r)
+}
+
+// EUFortnightDrivingRule - не больше 90 часов вождения за две недели подряд
+type EUFortnightDrivingRule struct{}
+
+func (EUFortnightDrivingRule) Name() string { return "eu_fortnight_driving" }
+
+func (r EUFortnightDrivingRule) Check(ps []path.Path) Verdict {
+	weeks, order := weeklyDriving(ps)
+	for _, w := range order {
+		next := w.AddDate(0, 0, 7)
+		if total := weeks[w] + weeks[next]; total > EUFortnightDriving {
+			return fail(r, "%s of driving in the weeks of %s and %s, limit is %s",
+				total, w.Format("02.01"), next.Format("02.01"), EUFortnightDriving)
+		}
+	}
+	return pass(r)
+}
+
+// weeklyDriving - вождение по неделям (ключ - полночь понедельника)
+// и недели в порядке появления
+func weeklyDriving(ps []path.Path) (map[time.Time]time.Duration, []time.Time) {
+	weeks := make(map[time.Time]time.Duration)
+	var order []time.Time
+	for _, p := range ps {
+		w := weekStart(p.StartTime)
+		if _, ok := weeks[w]; !ok {
+			order = append(order, w)
+		}
+		weeks[w] += p.EndTime.Sub(p.StartTime)
+	}
+	return weeks, order
+}
+
+// weekStart - полночь понедельника недели, в которую попадает t
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+func drivingOf(ps []path.Path) time.Duration {
+	var d time.Duration
+	for _, p := range ps {
+		d += p.EndTime.Sub(p.StartTime)
+	}
+	return d
+}
@@ -0,0 +1,105 @@
+package driver
+
+import (
+	"course/pkg/path"
+	"slices"
+	"testing"
+)
+
+// drivingDays - по одному пути с from до to в каждый из дней days
+func drivingDays(from, to string, days ...int) []path.Path {
+	ps := make([]path.Path, 0, len(days))
+	for i, d := range days {
+		ps = append(ps, trip(i+1, d, from, to))
+	}
+	return ps
+}
+
+func TestEURules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		ps   []path.Path
+		ok   bool
+	}{
+		{"break, 4.5h straight", EUBreakRule{}, []path.Path{trip(1, 0, "06:00", "10:30")}, true},
+		{"break, a minute over", EUBreakRule{}, []path.Path{trip(1, 0, "06:00", "10:31")}, false},
+		{"break, short gaps do not count", EUBreakRule{},
+			[]path.Path{trip(1, 0, "06:00", "09:00"), trip(2, 0, "09:10", "10:41")}, false},
+		{"break, 45 minutes resets", EUBreakRule{},
+			[]path.Path{trip(1, 0, "06:00", "10:00"), trip(2, 0, "10:45", "14:00")}, true},
+		{"break, split 15 then 30", EUBreakRule{},
+			[]path.Path{trip(1, 0, "06:00", "08:00"), trip(2, 0, "08:15", "10:00"), trip(3, 0, "10:30", "13:00")}, true},
+		{"break, split 30 then 15 does not count", EUBreakRule{},
+			[]path.Path{trip(1, 0, "06:00", "08:00"), trip(2, 0, "08:30", "10:00"), trip(3, 0, "10:15", "13:00")}, false},
+		{"break, new day resets", EUBreakRule{},
+			[]path.Path{trip(1, 0, "14:00", "18:00"), trip(2, 1, "06:00", "10:00")}, true},
+		// полночь не перерыв: ночная смена продолжает копить вождение
+		{"break, across midnight", EUBreakRule{},
+			[]path.Path{trip(1, 0, "21:00", "23:30"), trip(2, 1, "00:00", "02:01")}, false},
+
+		{"daily driving, 9h", EUDailyDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4), true},
+		{"daily driving, 10h twice a week", EUDailyDrivingRule{}, drivingDays("06:00", "16:00", 0, 1), true},
+		{"daily driving, over 10h", EUDailyDrivingRule{}, drivingDays("06:00", "16:01", 0), false},
+		{"daily driving, 10h three times a week", EUDailyDrivingRule{}, drivingDays("06:00", "16:00", 0, 1, 2), false},
+		{"daily driving, 10h twice in each of two weeks", EUDailyDrivingRule{}, drivingDays("06:00", "16:00", 0, 1, 7, 8), true},
+		{"daily driving, night shift over 10h", EUDailyDrivingRule{},
+			[]path.Path{trip(1, 0, "18:00", "23:30"), trip(2, 1, "00:00", "05:00")}, false},
+		{"daily driving, two shifts of one date", EUDailyDrivingRule{},
+			[]path.Path{trip(1, 0, "00:00", "05:00"), trip(2, 0, "14:00", "19:00")}, true},
+
+		{"weekly driving, six days of 9h", EUWeeklyDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4, 5), true},
+		{"weekly driving, seven days of 9h", EUWeeklyDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4, 5, 6), false},
+		{"weekly driving, split by Monday", EUWeeklyDrivingRule{}, drivingDays("06:00", "15:00", 3, 4, 5, 6, 7, 8, 9), true},
+
+		// 54 часа в первую неделю и 36 или 45 во вторую
+		{"fortnight driving, 90h", EUFortnightDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4, 5, 7, 8, 9, 10), true},
+		{"fortnight driving, 99h", EUFortnightDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4, 5, 7, 8, 9, 10, 11), false},
+		{"fortnight driving, weeks apart", EUFortnightDrivingRule{}, drivingDays("06:00", "15:00", 0, 1, 2, 3, 4, 5, 14, 15, 16, 17, 18), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if v := tc.rule.Check(tc.ps); v.OK != tc.ok {
+				t.Errorf("Check = %+v, want OK %v", v, tc.ok)
+			}
+		})
+	}
+}
+
+func TestProfile(t *testing.T) {
+	rules, ok := Profile("eu561")
+	if !ok || len(rules) != len(EU561()) {
+		t.Fatalf("Profile(eu561) = %d rules, %v", len(rules), ok)
+	}
+	if _, ok := Profile("unknown"); ok {
+		t.Errorf("Profile(unknown) found a profile")
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	spec := Spec{Rules: EU561()}
+	// пять смен по 10 часов: в этой неделе уже нарушено правило продленных смен
+	worked := drivingDays("06:00", "16:00", 0, 1, 2, 3, 4)
+	tests := []struct {
+		name   string
+		ps     []path.Path
+		failed []string
+	}{
+		{"light next week", drivingDays("06:00", "15:00", 7, 8, 9, 10), nil},
+		// 50 часов и еще 45 - больше 90 за две недели
+		{"heavy next week", drivingDays("06:00", "15:00", 7, 8, 9, 10, 11), []string{"eu_fortnight_driving"}},
+		// после смены до 16:00 в пятницу в 01:00 начинается новая: отдых 9 часов
+		{"short rest after the week", []path.Path{trip(9, 5, "01:00", "02:00")}, []string{"min_daily_rest"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var failed []string
+			for _, v := range spec.Conflicts(worked, tc.ps) {
+				failed = append(failed, v.Rule)
+			}
+			if !slices.Equal(failed, tc.failed) {
+				t.Errorf("Conflicts = %v, want %v", failed, tc.failed)
+			}
+		})
+	}
+}
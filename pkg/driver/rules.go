@@ -0,0 +1,299 @@
+package driver
+
+import (
+	"bytes"
+	"course/pkg/path"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Verdict - итог проверки путей водителя одним правилом
+type Verdict struct {
+	// имя правила, например "max_spread"
+	Rule   string
+	OK     bool
+	Reason string
+}
+
+// Rule - трудовое правило. Check получает пути водителя в порядке начала,
+// возможно за несколько дней; на смены их делит Shifts.
+type Rule interface {
+	Name() string
+	Check(ps []path.Path) Verdict
+}
+
+// Check проверяет пути по всем правилам типа и возвращает нарушенные.
+// Порядок ps не важен, исходный срез не меняется.
+func (s Spec) Check(ps []path.Path) []Verdict {
+	sorted := sortByStart(ps)
+	var failed []Verdict
+	for _, r := range s.Rules {
+		if v := r.Check(sorted); !v.OK {
+			failed = append(failed, v)
+		}
+	}
+	return failed
+}
+
+// Report - итог по каждому правилу типа, в том числе соблюденным:
+// по нему оператор показывает соответствие смены правилам
+func (s Spec) Report(ps []path.Path) []Verdict {
+	sorted := sortByStart(ps)
+	res := make([]Verdict, 0, len(s.Rules))
+	for _, r := range s.Rules {
+		res = append(res, r.Check(sorted))
+	}
+	return res
+}
+
+// Conflicts - правила типа, которые нарушат пути ps вместе с уже
+// отработанными worked, например за прошлую неделю. Правило, нарушенное
+// уже в worked, не считается: пути ps в этом не виноваты.
+func (s Spec) Conflicts(worked, ps []path.Path) []Verdict {
+	before := sortByStart(worked)
+	all := sortByStart(append(slices.Clip(worked), ps...))
+	var failed []Verdict
+	for _, r := range s.Rules {
+		if v := r.Check(all); !v.OK && r.Check(before).OK {
+			failed = append(failed, v)
+		}
+	}
+	return failed
+}
+
+// Legal - соблюдены ли все правила типа
+func (s Spec) Legal(ps []path.Path) bool {
+	sorted := sortByStart(ps)
+	for _, r := range s.Rules {
+		if !r.Check(sorted).OK {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultRules - правила типа по его параметрам: пути не пересекаются,
+// смена не длиннее WorkDur, вождение без перерыва RestDur не дольше
+// ContinuousDur, перерывов не больше RestCount, между сменами
+// не меньше MinDailyRest
+func DefaultRules(s Spec) []Rule {
+	rules := []Rule{
+		NoOverlap{},
+		MaxSpread{Limit: s.WorkDur},
+		MaxContinuous{Limit: s.ContinuousDur, Break: s.RestDur},
+		MaxBreaks{Count: int(s.RestCount), Break: s.RestDur},
+	}
+	if s.MinDailyRest > 0 {
+		rules = append(rules, MinDailyRest{Dur: s.MinDailyRest})
+	}
+	return rules
+}
+
+// NoOverlap - пути водителя не пересекаются
+type NoOverlap struct{}
+
+func (NoOverlap) Name() string { return "no_overlap" }
+
+func (r NoOverlap) Check(ps []path.Path) Verdict {
+	for i := 1; i < len(ps); i++ {
+		if ps[i].StartTime.Before(ps[i-1].EndTime) {
+			return fail(r, "path %d at %s starts before path %d ends at %s",
+				ps[i].Number, ps[i].StartTime.Format("15:04"),
+				ps[i-1].Number, ps[i-1].EndTime.Format("15:04"))
+		}
+	}
+	return pass(r)
+}
+
+// MaxSpread - смена от начала первого пути до конца последнего не длиннее Limit
+type MaxSpread struct {
+	Limit time.Duration
+}
+
+func (MaxSpread) Name() string { return "max_spread" }
+
+func (r MaxSpread) Check(ps []path.Path) Verdict {
+	for _, shift := range Shifts(ps) {
+		if spread := spreadOf(shift); spread > r.Limit {
+			return fail(r, "shift lasts %s, limit is %s", spread, r.Limit)
+		}
+	}
+	return pass(r)
+}
+
+// MaxContinuous - вождение без перерыва не короче Break не дольше Limit
+type MaxContinuous struct {
+	Limit time.Duration
+	Break time.Duration
+}
+
+func (MaxContinuous) Name() string { return "max_continuous" }
+
+func (r MaxContinuous) Check(ps []path.Path) Verdict {
+	n := 0
+	for _, shift := range Shifts(ps) {
+		for _, st := range stretches(shift, r.Break) {
+			if st > r.Limit {
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		return fail(r, "%d stretches longer than %s without a %s break", n, r.Limit, r.Break)
+	}
+	return pass(r)
+}
+
+// MinBreak - в смене, где вождения больше After, есть перерыв не короче Dur
+type MinBreak struct {
+	Dur   time.Duration
+	After time.Duration
+}
+
+func (MinBreak) Name() string { return "min_break" }
+
+func (r MinBreak) Check(ps []path.Path) Verdict {
+	for _, shift := range Shifts(ps) {
+		var driving, longest time.Duration
+		for i, p := range shift {
+			driving += p.EndTime.Sub(p.StartTime)
+			if i > 0 {
+				longest = max(longest, p.StartTime.Sub(shift[i-1].EndTime))
+			}
+		}
+		if driving > r.After && longest < r.Dur {
+			return fail(r, "%s of driving with no break of %s, longest is %s", driving, r.Dur, longest)
+		}
+	}
+	return pass(r)
+}
+
+// MaxBreaks - в смене не больше Count перерывов не короче Break
+type MaxBreaks struct {
+	Count int
+	Break time.Duration
+}
+
+func (MaxBreaks) Name() string { return "max_breaks" }
+
+func (r MaxBreaks) Check(ps []path.Path) Verdict {
+	for _, shift := range Shifts(ps) {
+		if n := len(stretches(shift, r.Break)) - 1; n > r.Count {
+			return fail(r, "%d breaks of %s, limit is %d", n, r.Break, r.Count)
+		}
+	}
+	return pass(r)
+}
+
+// MinDailyRest - между концом смены и началом следующей не меньше Dur.
+// Отдых короче ShiftGap - перерыв внутри смены, его ограничивает MaxSpread.
+type MinDailyRest struct {
+	Dur time.Duration
+}
+
+func (MinDailyRest) Name() string { return "min_daily_rest" }
+
+func (r MinDailyRest) Check(ps []path.Path) Verdict {
+	shifts := Shifts(ps)
+	for i := 1; i < len(shifts); i++ {
+		prev := shifts[i-1]
+		end := shiftEnd(prev)
+		if rest := shifts[i][0].StartTime.Sub(end); rest < r.Dur {
+			return fail(r, "rest from %s to %s is %s, minimum is %s",
+				end.Format("02.01 15:04"), shifts[i][0].StartTime.Format("02.01 15:04"), rest, r.Dur)
+		}
+	}
+	return pass(r)
+}
+
+// ShiftGap - перерыв, с которого начинается новая смена: сокращенный
+// ежедневный отдых. Более короткий перерыв остается внутри смены, даже если
+// смена переходит через полночь.
+const ShiftGap = 9 * time.Hour
+
+// Shifts делит пути, упорядоченные по началу, на смены: новая смена
+// начинается после перерыва не короче ShiftGap от конца предыдущей.
+// Смены - части ps без копирования, их нельзя менять.
+func Shifts(ps []path.Path) [][]path.Path {
+	var (
+		shifts [][]path.Path
+		from   int
+		end    time.Time
+	)
+	for i, p := range ps {
+		if i > 0 && p.StartTime.Sub(end) >= ShiftGap {
+			shifts = append(shifts, ps[from:i:i])
+			from = i
+		}
+		if i == from || p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	if len(ps) > 0 {
+		shifts = append(shifts, ps[from:len(ps):len(ps)])
+	}
+	return shifts
+}
+
+// stretches - отрезки непрерывного вождения смены между перерывами не короче brk
+func stretches(shift []path.Path, brk time.Duration) []time.Duration {
+	var (
+		res     []time.Duration
+		stretch time.Duration
+	)
+	for i, p := range shift {
+		if i > 0 && p.StartTime.Sub(shift[i-1].EndTime) >= brk {
+			res = append(res, stretch)
+			stretch = 0
+		}
+		stretch += p.EndTime.Sub(p.StartTime)
+	}
+	if len(shift) > 0 {
+		res = append(res, stretch)
+	}
+	return res
+}
+
+func spreadOf(shift []path.Path) time.Duration {
+	if len(shift) == 0 {
+		return 0
+	}
+	return shiftEnd(shift).Sub(shift[0].StartTime)
+}
+
+func shiftEnd(shift []path.Path) time.Time {
+	end := shift[0].EndTime
+	for _, p := range shift[1:] {
+		if p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	return end
+}
+
+// sortByStart - пути по началу, при равном начале - по идентификатору.
+// Уже упорядоченный срез возвращается без копии: правила его не меняют.
+func sortByStart(ps []path.Path) []path.Path {
+	if slices.IsSortedFunc(ps, compareStart) {
+		return ps
+	}
+	sorted := slices.Clone(ps)
+	slices.SortFunc(sorted, compareStart)
+	return sorted
+}
+
+// compareStart - порядок путей по началу, а при равном начале - по идентификатору.
+// Байты UUID сравниваются так же, как его строки, но без выделения памяти.
+func compareStart(a, b path.Path) int {
+	if c := a.StartTime.Compare(b.StartTime); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func pass(r Rule) Verdict { return Verdict{Rule: r.Name(), OK: true} }
+
+func fail(r Rule, format string, args ...any) Verdict {
+	return Verdict{Rule: r.Name(), Reason: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,157 @@
+package driver
+
+import (
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+	"time"
+)
+
+// monday - полночь понедельника, от которой отсчитываются дни путей в тестах
+var monday = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// trip - путь номер n в день day (0 - monday) с from до to, время вида "15:04"
+func trip(n, day int, from, to string) path.Path {
+	return path.Path{ID: uuid.UUID{byte(n)}, Number: n, StartTime: at(day, from), EndTime: at(day, to)}
+}
+
+func at(day int, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		panic(err)
+	}
+	return monday.AddDate(0, 0, day).Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+}
+
+func TestShifts(t *testing.T) {
+	tests := []struct {
+		name string
+		ps   []path.Path
+		// номер последнего пути каждой смены
+		want []int
+	}{
+		{"empty", nil, nil},
+		{"one shift", []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "15:00", "15:30")}, []int{2}},
+		{"two days", []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 1, "06:00", "07:00"), trip(3, 1, "08:00", "09:00")}, []int{1, 3}},
+		{"gap of a day", []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 2, "06:00", "07:00")}, []int{1, 2}},
+		{"across midnight", []path.Path{trip(1, 0, "22:00", "23:30"), trip(2, 1, "00:30", "02:00")}, []int{2}},
+		{"gap just under ShiftGap", []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "15:59", "17:00")}, []int{2}},
+		{"gap of ShiftGap", []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "16:00", "17:00")}, []int{1, 2}},
+		// перерыв считается от самого позднего конца, а не от конца предыдущего пути
+		{"long path inside", []path.Path{trip(1, 0, "06:00", "20:00"), trip(2, 0, "07:00", "08:00"), trip(3, 1, "04:00", "05:00")}, []int{3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []int
+			for _, sh := range Shifts(tc.ps) {
+				got = append(got, sh[len(sh)-1].Number)
+				if cap(sh) != len(sh) {
+					t.Errorf("shift ending with path %d has cap %d, len %d", sh[len(sh)-1].Number, cap(sh), len(sh))
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("last paths of shifts = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		ps   []path.Path
+		ok   bool
+	}{
+		{"no overlap, back to back", NoOverlap{}, []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "07:00", "08:00")}, true},
+		{"no overlap, overlapping", NoOverlap{}, []path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "06:59", "08:00")}, false},
+
+		{"max spread, at the limit", MaxSpread{Limit: 8 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "13:00", "14:00")}, true},
+		{"max spread, a minute over", MaxSpread{Limit: 8 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "13:00", "14:01")}, false},
+		{"max spread, per day", MaxSpread{Limit: 8 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "14:00"), trip(2, 1, "06:00", "14:00")}, true},
+		// ночная смена не делится полночью
+		{"max spread, across midnight", MaxSpread{Limit: 8 * time.Hour},
+			[]path.Path{trip(1, 0, "20:00", "23:00"), trip(2, 1, "01:00", "04:30")}, false},
+
+		{"max continuous, short gaps add up", MaxContinuous{Limit: 4 * time.Hour, Break: 30 * time.Minute},
+			[]path.Path{trip(1, 0, "06:00", "08:00"), trip(2, 0, "08:15", "10:15"), trip(3, 0, "10:30", "11:00")}, false},
+		{"max continuous, break resets", MaxContinuous{Limit: 4 * time.Hour, Break: 30 * time.Minute},
+			[]path.Path{trip(1, 0, "06:00", "08:00"), trip(2, 0, "08:15", "10:15"), trip(3, 0, "10:45", "12:00")}, true},
+
+		{"max breaks, within the count", MaxBreaks{Count: 1, Break: time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "08:00", "09:00"), trip(3, 0, "09:30", "10:00")}, true},
+		{"max breaks, one too many", MaxBreaks{Count: 1, Break: time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "07:00"), trip(2, 0, "08:00", "09:00"), trip(3, 0, "10:00", "11:00")}, false},
+
+		{"min break, short shift", MinBreak{Dur: 30 * time.Minute, After: 6 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "09:00"), trip(2, 0, "09:00", "12:00")}, true},
+		{"min break, long shift without it", MinBreak{Dur: 30 * time.Minute, After: 6 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "09:00"), trip(2, 0, "09:15", "12:30")}, false},
+		{"min break, long shift with it", MinBreak{Dur: 30 * time.Minute, After: 6 * time.Hour},
+			[]path.Path{trip(1, 0, "06:00", "09:00"), trip(2, 0, "09:30", "12:45")}, true},
+
+		{"min daily rest, too short", MinDailyRest{Dur: 11 * time.Hour},
+			[]path.Path{trip(1, 0, "20:00", "22:00"), trip(2, 1, "08:00", "09:00")}, false},
+		{"min daily rest, enough", MinDailyRest{Dur: 11 * time.Hour},
+			[]path.Path{trip(1, 0, "20:00", "22:00"), trip(2, 1, "09:00", "10:00")}, true},
+		// после полуночи тот же водитель продолжает смену, а не начинает новую
+		{"min daily rest, shift across midnight", MinDailyRest{Dur: 11 * time.Hour},
+			[]path.Path{trip(1, 0, "20:00", "23:00"), trip(2, 1, "00:30", "02:00"), trip(3, 1, "14:00", "15:00")}, true},
+		{"min daily rest, short rest after a night shift", MinDailyRest{Dur: 11 * time.Hour},
+			[]path.Path{trip(1, 0, "20:00", "23:00"), trip(2, 1, "00:30", "02:00"), trip(3, 1, "12:00", "13:00")}, false},
+		{"max breaks, across midnight", MaxBreaks{Count: 1, Break: time.Hour},
+			[]path.Path{trip(1, 0, "21:00", "22:00"), trip(2, 0, "23:00", "23:30"), trip(3, 1, "00:30", "01:00")}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := tc.rule.Check(tc.ps)
+			if v.OK != tc.ok {
+				t.Errorf("Check = %+v, want OK %v", v, tc.ok)
+			}
+			if v.Rule != tc.rule.Name() {
+				t.Errorf("verdict rule %q, want %q", v.Rule, tc.rule.Name())
+			}
+			if !v.OK && v.Reason == "" {
+				t.Errorf("failed verdict has no reason")
+			}
+		})
+	}
+}
+
+func TestSpecCheck(t *testing.T) {
+	spec := Spec{Rules: []Rule{NoOverlap{}, MaxSpread{Limit: 8 * time.Hour}}}
+	tests := []struct {
+		name   string
+		ps     []path.Path
+		failed []string
+	}{
+		{"legal out of order", []path.Path{trip(2, 0, "10:00", "11:00"), trip(1, 0, "06:00", "07:00")}, nil},
+		{"overlap out of order", []path.Path{trip(2, 0, "06:30", "07:30"), trip(1, 0, "06:00", "07:00")}, []string{"no_overlap"}},
+		{"both broken", []path.Path{trip(1, 0, "06:00", "10:00"), trip(2, 0, "09:00", "15:00")}, []string{"no_overlap", "max_spread"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before := slices.Clone(tc.ps)
+			var failed []string
+			for _, v := range spec.Check(tc.ps) {
+				failed = append(failed, v.Rule)
+			}
+			if !slices.Equal(failed, tc.failed) {
+				t.Errorf("Check failed %v, want %v", failed, tc.failed)
+			}
+			if got := spec.Legal(tc.ps); got != (len(tc.failed) == 0) {
+				t.Errorf("Legal = %v, want %v", got, len(tc.failed) == 0)
+			}
+			if got := len(spec.Report(tc.ps)); got != len(spec.Rules) {
+				t.Errorf("Report has %d verdicts, want %d", got, len(spec.Rules))
+			}
+			if !slices.EqualFunc(tc.ps, before, func(a, b path.Path) bool { return a.ID == b.ID }) {
+				t.Errorf("Check reordered its input")
+			}
+		})
+	}
+}
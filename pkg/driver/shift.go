@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"course/pkg/path"
+	"slices"
+	"time"
+)
+
+// Shift - рабочий день водителя, собранный из его путей
+type Shift struct {
+	drv   Driver
+	paths []path.Path
+
+	// время вождения без учета перерывов
+	driving time.Duration
+	// перерывы не короче RestDur
+	breaks    int
+	breakTime time.Duration
+	// непрерывное вождение между перерывами
+	stretches []time.Duration
+}
+
+// NewShift строит смену водителя d по его путям.
+// Пути сортируются по времени начала, а одновременные - по идентификатору,
+// чтобы смена не зависела от порядка ps. Исходный срез не меняется.
+func NewShift(d Driver, ps []path.Path) Shift {
+	s := Shift{
+		drv:   d,
+		paths: slices.Clone(ps),
+	}
+	slices.SortFunc(s.paths, compareStart)
+
+	var stretch time.Duration
+	for i, p := range s.paths {
+		if i > 0 {
+			gap := p.StartTime.Sub(s.paths[i-1].EndTime)
+			if gap >= d.RestDur() {
+				s.breaks++
+				s.breakTime += gap
+				s.stretches = append(s.stretches, stretch)
+				stretch = 0
+			}
+		}
+		dur := p.EndTime.Sub(p.StartTime)
+		s.driving += dur
+		stretch += dur
+	}
+	if len(s.paths) > 0 {
+		s.stretches = append(s.stretches, stretch)
+	}
+	return s
+}
+
+// Paths - пути смены в порядке начала
+func (s Shift) Paths() []path.Path { return s.paths }
+
+func (s Shift) Driving() time.Duration { return s.driving }
+
+func (s Shift) Breaks() int { return s.breaks }
+
+// Spread - продолжительность смены от начала первого пути до конца последнего
+func (s Shift) Spread() time.Duration {
+	if len(s.paths) == 0 {
+		return 0
+	}
+	end := s.paths[0].EndTime
+	for _, p := range s.paths[1:] {
+		if p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	return end.Sub(s.paths[0].StartTime)
+}
+
+// Overtime - насколько смена длиннее WorkDur
+func (s Shift) Overtime() time.Duration {
+	return max(s.Spread()-s.drv.WorkDur(), 0)
+}
+
+// RestViolations - количество отрезков непрерывного вождения,
+// превысивших ContinuousDur
+func (s Shift) RestViolations() int {
+	n := 0
+	for _, st := range s.stretches {
+		if st > s.drv.ContinuousDur() {
+			n++
+		}
+	}
+	return n
+}
+
+// Idle - оплачиваемое время смены без вождения.
+// Положенные перерывы (не больше RestCount по RestDur) простоем не считаются.
+func (s Shift) Idle() time.Duration {
+	rest := time.Duration(min(int64(s.breaks), s.drv.RestCount())) * s.drv.RestDur()
+	return max(s.Spread()-s.driving-rest, 0)
+}
+
+// Legal - смена соблюдает все правила типа водителя
+func (s Shift) Legal() bool {
+	return s.drv.Spec().Legal(s.paths)
+}
+
+// Violations - нарушенные правила типа водителя
+func (s Shift) Violations() []Verdict {
+	return s.drv.Spec().Check(s.paths)
+}
@@ -0,0 +1,212 @@
+package driver
+
+import (
+	"course/config"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"slices"
+	"time"
+)
+
+// Spec - тип водителя: трудовые правила и оплата
+type Spec struct {
+	Name DriverType
+	// максимальная длина смены
+	WorkDur time.Duration
+	// минимальный перерыв
+	RestDur   time.Duration
+	RestCount int64
+	// максимальное время вождения без перерыва
+	ContinuousDur time.Duration
+	WorkDays      int
+	WeekendDays   int
+	// минимальный отдых между сменами
+	MinDailyRest time.Duration
+	// максимум рабочего времени за календарную неделю
+	WeeklyCap time.Duration
+	// стоимость часа смены
+	HourlyCost float64
+	// правила, по которым проверяются смены водителей этого типа
+	Rules []Rule
+}
+
+// ShiftCost - стоимость смены: смена оплачивается целиком, на все WorkDur
+func (s Spec) ShiftCost() float64 {
+	return s.HourlyCost * s.WorkDur.Hours()
+}
+
+var types []Spec
+
+// Types - типы водителей из конфигурации, в ее порядке
+func Types() []Spec { return slices.Clone(types) }
+
+// Lookup - тип водителя по имени
+func Lookup(name DriverType) (Spec, bool) {
+	for _, s := range types {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Spec{}, false
+}
+
+func init() {
+	var err error
+	types, err = FromConfig(config.C().DriverTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// DefaultTypes - типы водителей, если в конфигурации они не заданы:
+// A работает восемь часов с одним длинным перерывом,
+// B - весь день с частыми короткими
+func DefaultTypes() []Spec {
+	specs := []Spec{
+		{
+			Name:          "A",
+			WorkDur:       8 * time.Hour,
+			RestDur:       time.Hour,
+			RestCount:     1,
+			ContinuousDur: 4 * time.Hour,
+			WorkDays:      5,
+			WeekendDays:   2,
+			MinDailyRest:  11 * time.Hour,
+			WeeklyCap:     40 * time.Hour,
+			HourlyCost:    125,
+		},
+		{
+			Name:          "B",
+			WorkDur:       18 * time.Hour,
+			RestDur:       20 * time.Minute,
+			RestCount:     12,
+			ContinuousDur: 3 * time.Hour,
+			WorkDays:      5,
+			WeekendDays:   2,
+			MinDailyRest:  6 * time.Hour,
+			WeeklyCap:     60 * time.Hour,
+			HourlyCost:    1200.0 / 18,
+		},
+	}
+	for i := range specs {
+		specs[i].Rules = DefaultRules(specs[i])
+	}
+	return specs
+}
+
+// FromConfig берет типы из конфигурации. Без типов в конфигурации
+// действуют DefaultTypes, незаданные поля типа берутся у первого из них.
+// Тип без профиля и списка правил получает DefaultRules по своим параметрам;
+// правила из списка добавляются к правилам профиля.
+func FromConfig(c []config.DriverTypeConfig) ([]Spec, error) {
+	if len(c) == 0 {
+		return DefaultTypes(), nil
+	}
+	specs := make([]Spec, 0, len(c))
+	for _, tc := range c {
+		s := DefaultTypes()[0]
+		s.Name = tc.Name
+		if tc.WorkDurMin > 0 {
+			s.WorkDur = time.Duration(tc.WorkDurMin) * time.Minute
+		}
+		if tc.RestDurMin > 0 {
+			s.RestDur = time.Duration(tc.RestDurMin) * time.Minute
+		}
+		if tc.RestCount > 0 {
+			s.RestCount = tc.RestCount
+		}
+		if tc.ContinuousDurMin > 0 {
+			s.ContinuousDur = time.Duration(tc.ContinuousDurMin) * time.Minute
+		}
+		if tc.WorkDays > 0 {
+			s.WorkDays = tc.WorkDays
+		}
+		if tc.WeekendDays > 0 {
+			s.WeekendDays = tc.WeekendDays
+		}
+		if tc.MinDailyRestMin > 0 {
+			s.MinDailyRest = time.Duration(tc.MinDailyRestMin) * time.Minute
+		}
+		// без своего лимита неделя - WorkDays полных смен
+		s.WeeklyCap = time.Duration(s.WorkDays) * s.WorkDur
+		if tc.WeeklyCapMin > 0 {
+			s.WeeklyCap = time.Duration(tc.WeeklyCapMin) * time.Minute
+		}
+		if tc.HourlyCost > 0 {
+			s.HourlyCost = tc.HourlyCost
+		}
+
+		s.Rules = DefaultRules(s)
+		if tc.Profile != "" || len(tc.Rules) > 0 {
+			s.Rules = nil
+			if tc.Profile != "" {
+				rules, ok := Profile(tc.Profile)
+				if !ok {
+					return nil, fmt.Errorf("driver type %q: unknown profile %q", tc.Name, tc.Profile)
+				}
+				s.Rules = rules
+			}
+			for _, rc := range tc.Rules {
+				r, err := RuleFromConfig(rc)
+				if err != nil {
+					return nil, fmt.Errorf("driver type %q: %w", tc.Name, err)
+				}
+				s.Rules = append(s.Rules, r)
+			}
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+// RuleFromConfig строит правило по его описанию в конфигурации
+func RuleFromConfig(rc config.RuleConfig) (Rule, error) {
+	minutes := func(n int) time.Duration { return time.Duration(n) * time.Minute }
+	switch rc.Rule {
+	case NoOverlap{}.Name():
+		return NoOverlap{}, nil
+	case MaxSpread{}.Name():
+		return MaxSpread{Limit: minutes(rc.LimitMin)}, nil
+	case MaxContinuous{}.Name():
+		return MaxContinuous{Limit: minutes(rc.LimitMin), Break: minutes(rc.BreakMin)}, nil
+	case MinBreak{}.Name():
+		return MinBreak{Dur: minutes(rc.BreakMin), After: minutes(rc.LimitMin)}, nil
+	case MaxBreaks{}.Name():
+		return MaxBreaks{Count: rc.Count, Break: minutes(rc.BreakMin)}, nil
+	case MinDailyRest{}.Name():
+		return MinDailyRest{Dur: minutes(rc.LimitMin)}, nil
+	case EUBreakRule{}.Name():
+		return EUBreakRule{}, nil
+	case EUDailyDrivingRule{}.Name():
+		return EUDailyDrivingRule{}, nil
+	case EUWeeklyDrivingRule{}.Name():
+		return EUWeeklyDrivingRule{}, nil
+	case EUFortnightDrivingRule{}.Name():
+		return EUFortnightDrivingRule{}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule %q", rc.Rule)
+	}
+}
+
+// Longest - тип с самой длинной сменой: его водитель может покрыть
+// больше всего путей за день
+func Longest() Spec {
+	best := types[0]
+	for _, s := range types[1:] {
+		if s.WorkDur > best.WorkDur {
+			best = s
+		}
+	}
+	return best
+}
+
+// Prototypes - по водителю каждого типа, в порядке Types. Нужны, чтобы
+// примерять смены к типам до найма.
+func Prototypes() []Driver {
+	ds := make([]Driver, 0, len(types))
+	for _, s := range types {
+		ds = append(ds, newDriver(uuid.Nil, s))
+	}
+	return ds
+}
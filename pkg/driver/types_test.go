@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"course/config"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestFromConfig(t *testing.T) {
+	if got, err := FromConfig(nil); err != nil || len(got) != len(DefaultTypes()) {
+		t.Fatalf("FromConfig(nil) = %d types, want the %d default ones", len(got), len(DefaultTypes()))
+	}
+
+	// незаданные поля берутся у первого типа по умолчанию
+	got, err := FromConfig([]config.DriverTypeConfig{{Name: "N", WorkDurMin: 600, HourlyCost: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := DefaultTypes()[0]
+	if len(got) != 1 || got[0].Name != "N" || got[0].WorkDur != 10*time.Hour || got[0].HourlyCost != 100 ||
+		got[0].RestDur != def.RestDur || got[0].ContinuousDur != def.ContinuousDur {
+		t.Fatalf("FromConfig = %+v", got)
+	}
+	if c := got[0].ShiftCost(); c != 1000 {
+		t.Errorf("ShiftCost = %v, want 1000", c)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, s := range Types() {
+		d := New(uuid.UUID{1}, s.Name)
+		if d.Type() != s.Name || d.Spec().WorkDur != s.WorkDur || d.ID() != (uuid.UUID{1}) {
+			t.Errorf("New(%q) = %s %s", s.Name, d.Type(), d.ID())
+		}
+	}
+	for _, s := range Types() {
+		if s.WorkDur > Longest().WorkDur {
+			t.Errorf("Longest = %q, but %q works longer", Longest().Name, s.Name)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New with an unknown type did not panic")
+		}
+	}()
+	New(uuid.UUID{1}, "no such type")
+}
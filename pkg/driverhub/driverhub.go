@@ -3,18 +3,30 @@ package driverhub
 import (
 	"course/pkg/driver"
 	"course/pkg/path"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/random"
+	"course/pkg/timetable"
 	"github.com/google/uuid"
 	"maps"
+	"slices"
+	"strings"
 	"sync"
-	"time"
 )
 
 type DriverHub struct {
-	drivers map[uuid.UUID]driver.Driver
-	mu      sync.RWMutex
+	// депо, к которому приписан штат; uuid.Nil - общий штат нескольких депо
+	stationID uuid.UUID
+	drivers   map[uuid.UUID]driver.Driver
+	mu        sync.RWMutex
+	ids       *random.Source
 }
 
+func (dh *DriverHub) StationID() uuid.UUID {
+	return dh.stationID
+}
+
+// NewID - идентификатор для нового водителя этого хаба
+func (dh *DriverHub) NewID() uuid.UUID { return dh.ids.UUID() }
+
 func (dh *DriverHub) Drivers() map[uuid.UUID]driver.Driver {
 	res := make(map[uuid.UUID]driver.Driver)
 	dh.mu.RLock()
@@ -36,32 +48,40 @@ func (dh *DriverHub) Register(driver driver.Driver) {
 	dh.drivers[driver.ID()] = driver
 }
 
+// Unregister увольняет водителя
+func (dh *DriverHub) Unregister(id uuid.UUID) {
+	dh.mu.Lock()
+	defer dh.mu.Unlock()
+	delete(dh.drivers, id)
+}
+
+// GetNotInWork - водитель, который не занят в начале пути p, успевает
+// после своего предыдущего пути добраться холостым перегоном до начала p
+// и может взять p, не нарушив правил своего типа
 func (dh *DriverHub) GetNotInWork(
-	tt *ttv1.TimeTable,
-	timeTo time.Time,
+	tt timetable.Timetable,
+	p path.Path,
 ) driver.Driver {
+	timeTo := p.StartTime
 	drvs := dh.GetEach(func(d driver.Driver) bool {
-		return !tt.DriverOnTheWayToTime(timeTo, d.ID())
+		return !tt.DriverOnTheWayToTime(timeTo, d.ID()) && reachable(tt, p, d.ID())
 	})
 
 	if len(drvs) == 0 {
 		return nil
 	}
 
-	for _, drv := range drvs {
-		ps := tt.GetEach(func(p path.Path) bool {
-			return p.DriverID == drv.ID()
-		})
-
+	for _, id := range sortedIDs(drvs) {
+		drv := drvs[id]
 		var pss []path.Path
-		for _, v := range ps {
+		for _, v := range tt.DriverPaths(drv.ID()) {
 			if v.EndTime.Before(timeTo) {
 				pss = append(pss, v)
 			}
 		}
 
-		if drv.NeedsRest(pss) {
-			return nil
+		if drv.NeedsRest(append(pss, p)) {
+			continue
 		}
 
 		return drv
@@ -84,10 +104,28 @@ func (dh *DriverHub) GetEach(fn func(d driver.Driver) bool) map[uuid.UUID]driver
 func (dh *DriverHub) GetFirst(fn func(d driver.Driver) bool) uuid.UUID {
 	dh.mu.RLock()
 	defer dh.mu.RUnlock()
-	for k, v := range dh.drivers {
-		if fn(v) {
+	for _, k := range sortedIDs(dh.drivers) {
+		if fn(dh.drivers[k]) {
 			return k
 		}
 	}
 	return uuid.Nil
 }
+
+// reachable - успевает ли водитель от конца своего последнего пути,
+// закончившегося до начала p, к началу p
+func reachable(tt timetable.Timetable, p path.Path, driverID uuid.UUID) bool {
+	prev, found := tt.DriverPathBefore(driverID, p.StartTime)
+	return !found || tt.Follows(prev, p)
+}
+
+// sortedIDs - ключи в постоянном порядке, чтобы выбор водителя не зависел
+// от порядка обхода map
+func sortedIDs(drivers map[uuid.UUID]driver.Driver) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(drivers))
+	for id := range drivers {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
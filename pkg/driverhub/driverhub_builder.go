@@ -2,6 +2,7 @@ package driverhub
 
 import (
 	"course/pkg/driver"
+	"course/pkg/random"
 	"github.com/google/uuid"
 	"maps"
 	"sync"
@@ -11,6 +12,8 @@ type DriverHubBuilder struct {
 	stationID uuid.UUID
 	drivers   map[uuid.UUID]driver.Driver
 	mu        sync.Mutex
+	// из него выводятся генераторы идентификаторов собранных хабов
+	ids *random.Source
 
 	sets driverHubSets
 }
@@ -22,13 +25,28 @@ func NewDriverHubBuilder() *DriverHubBuilder {
 }
 
 func (dh *DriverHubBuilder) Build() *DriverHub {
+	dh.mu.Lock()
+	defer dh.mu.Unlock()
+	seed := uint64(0)
+	if dh.ids != nil {
+		seed = dh.ids.Uint64()
+	}
 	d := DriverHub{
-		drivers: make(map[uuid.UUID]driver.Driver),
+		stationID: dh.stationID,
+		drivers:   make(map[uuid.UUID]driver.Driver),
+		ids:       random.New(seed),
 	}
 	maps.Copy(d.drivers, dh.drivers)
 	return &d
 }
 
+// SetStation привязывает штат к депо - конечной stationID
+func (dh *DriverHubBuilder) SetStation(stationID uuid.UUID) {
+	dh.mu.Lock()
+	defer dh.mu.Unlock()
+	dh.stationID = stationID
+}
+
 type driverHubSets struct {
 	autoHire bool
 }
@@ -38,3 +56,13 @@ func (dh *DriverHubBuilder) AddDriver(d driver.Driver) {
 	defer dh.mu.Unlock()
 	dh.drivers[d.ID()] = d
 }
+
+// SetSeed задает зерно идентификаторов, которые выдают собранные хабы.
+// Каждая следующая сборка получает свой генератор, выведенный из зерна,
+// поэтому при одном зерне и одном порядке сборок идентификаторы повторяются.
+// Без зерна идентификаторы случайные.
+func (dh *DriverHubBuilder) SetSeed(seed uint64) {
+	dh.mu.Lock()
+	defer dh.mu.Unlock()
+	dh.ids = random.New(random.Derive(seed, "drivers"))
+}
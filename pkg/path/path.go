@@ -1,8 +1,8 @@
 package path
 
 import (
+	"course/pkg/random"
 	"github.com/google/uuid"
-	"math/rand"
 	"time"
 )
 
@@ -42,13 +42,13 @@ func (p *Path) Last() Station {
 	return &p.Points[len(p.Points)-1]
 }
 
-func (p *Path) GenDstItems() []DstItem {
+func (p *Path) GenDstItems(rnd *random.Source) []DstItem {
 	dstis := make([]DstItem, len(p.Points)-1)
 	for i := 1; i < len(p.Points)-1; i++ {
 		dstis[i] = DstItem{
 			To:   p.Points[i].ID(),
 			From: p.Points[i-1].ID(),
-			Dur:  time.Duration(rand.Intn(12)+3) * time.Minute,
+			Dur:  time.Duration(rnd.IntN(12)+3) * time.Minute,
 		}
 	}
 	return dstis
@@ -59,19 +59,20 @@ func NewPath(
 	number int,
 	stations int,
 	startTime time.Time,
+	rnd *random.Source,
 ) Path {
 	points := make([]Point, stations)
 	for i := 0; i < stations; i++ {
 		points[i] = Point{
-			Id:   uuid.New(),
-			Name: randomName(12),
+			Id:   rnd.UUID(),
+			Name: randomName(rnd, 12),
 		}
 	}
 	points[0] = src
 	points[len(points)-1] = dst
 
 	return Path{
-		ID:        uuid.New(),
+		ID:        rnd.UUID(),
 		Number:    number,
 		StartTime: startTime,
 		Points:    points,
@@ -80,10 +81,10 @@ func NewPath(
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
-func randomName(n int) string {
+func randomName(rnd *random.Source, n int) string {
 	b := make([]byte, n)
 	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
+		b[i] = letterBytes[rnd.IntN(len(letterBytes))]
 	}
 	return string(b)
 }
@@ -0,0 +1,82 @@
+package random
+
+import (
+	"encoding/binary"
+	"github.com/google/uuid"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+)
+
+// Source - источник случайности эксперимента. Сцена и оптимизаторы берут
+// случайные числа только из него, поэтому эксперимент повторяется по зерну.
+// Безопасен для одновременного использования.
+type Source struct {
+	seed uint64
+	mu   sync.Mutex
+	rnd  *rand.Rand
+}
+
+// New создает источник с зерном seed; 0 - случайное зерно
+func New(seed uint64) *Source {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Source{
+		seed: seed,
+		rnd:  rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Seed - зерно, по которому источник можно воссоздать
+func (s *Source) Seed() uint64 { return s.seed }
+
+func (s *Source) IntN(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.IntN(n)
+}
+
+func (s *Source) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Uint64()
+}
+
+func (s *Source) Float64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Float64()
+}
+
+// Read заполняет p случайными байтами, ошибки не бывает.
+// Так источник подходит для uuid.NewRandomFromReader.
+func (s *Source) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var buf [8]byte
+	for i := 0; i < len(p); i += len(buf) {
+		binary.LittleEndian.PutUint64(buf[:], s.rnd.Uint64())
+		copy(p[i:], buf[:])
+	}
+	return len(p), nil
+}
+
+// UUID - случайный идентификатор из этого источника
+func (s *Source) UUID() uuid.UUID {
+	return uuid.Must(uuid.NewRandomFromReader(s))
+}
+
+// Derive - зерно для именованной части эксперимента, например одного оптимизатора.
+// Не зависит от того, в каком порядке запускаются части.
+func Derive(seed uint64, name string) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], seed)
+	h.Write(buf[:])
+	h.Write([]byte(name))
+	if v := h.Sum64(); v != 0 {
+		return v
+	}
+	return 1
+}
@@ -0,0 +1,35 @@
+package random
+
+import (
+	"testing"
+)
+
+func TestSourceRepeatsBySeed(t *testing.T) {
+	a, b := New(42), New(42)
+	for i := 0; i < 100; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("step %d: %d != %d", i, x, y)
+		}
+	}
+	if x, y := a.UUID(), b.UUID(); x != y {
+		t.Errorf("UUID: %s != %s", x, y)
+	}
+	if New(42).Uint64() == New(43).Uint64() {
+		t.Error("different seeds give the same first value")
+	}
+	if s := New(0).Seed(); s == 0 {
+		t.Error("zero seed is not replaced with a random one")
+	}
+}
+
+func TestDerive(t *testing.T) {
+	if Derive(1, "greedy") != Derive(1, "greedy") {
+		t.Error("Derive is not stable")
+	}
+	if Derive(1, "greedy") == Derive(1, "tabu_gr") {
+		t.Error("Derive does not depend on the name")
+	}
+	if Derive(1, "greedy") == Derive(2, "greedy") {
+		t.Error("Derive does not depend on the seed")
+	}
+}
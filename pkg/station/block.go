@@ -0,0 +1,139 @@
+package station
+
+import (
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Block - рабочий день автобуса: выезд из депо, пути по времени начала
+// и возврат в депо. Холостые перегоны между путями - в timetable.DeadheadMoves.
+type Block struct {
+	BusID uuid.UUID
+	Depot path.Point
+	Paths []path.Path
+	// выезд из депо к началу первого пути
+	PullOut timetable.DeadheadMove
+	// возврат в депо после конца последнего пути
+	PullIn timetable.DeadheadMove
+	// false, если депо не связано с первой или последней точкой блока
+	Reachable bool
+}
+
+// Start - время выезда из депо
+func (b Block) Start() time.Time { return b.PullOut.Depart }
+
+// End - время возврата в депо
+func (b Block) End() time.Time { return b.PullIn.Arrive }
+
+// Duration - машинное время блока вместе с выездом и возвратом
+func (b Block) Duration() time.Duration {
+	if len(b.Paths) == 0 {
+		return 0
+	}
+	return b.End().Sub(b.Start())
+}
+
+// Block - блок автобуса busID по текущему назначению расписания.
+// У автобуса без путей блок пустой.
+func (bst *BusStation) Block(tt timetable.Timetable, busID uuid.UUID) Block {
+	blk := Block{BusID: busID, Depot: bst.depotOf(busID), Reachable: true}
+	blk.Paths = tt.BusPaths(busID)
+	if len(blk.Paths) == 0 {
+		return blk
+	}
+
+	first, last := blk.Paths[0], blk.Paths[len(blk.Paths)-1]
+	var out, in time.Duration
+	// без депо автобус выходит прямо к первому пути и остается у последнего
+	if blk.Depot.ID() != uuid.Nil {
+		var okOut, okIn bool
+		out, okOut = tt.Deadhead(blk.Depot.ID(), first.Points[0].ID())
+		in, okIn = tt.Deadhead(last.Points[len(last.Points)-1].ID(), blk.Depot.ID())
+		blk.Reachable = okOut && okIn
+	}
+	blk.PullOut = timetable.DeadheadMove{
+		BusID:      busID,
+		From:       blk.Depot,
+		To:         first.Points[0],
+		Depart:     first.StartTime.Add(-out),
+		Arrive:     first.StartTime,
+		NextPathID: first.ID,
+	}
+	blk.PullIn = timetable.DeadheadMove{
+		BusID:  busID,
+		From:   last.Points[len(last.Points)-1],
+		To:     blk.Depot,
+		Depart: last.EndTime,
+		Arrive: last.EndTime.Add(in),
+	}
+	return blk
+}
+
+// Blocks - блоки всех автобусов парка, в порядке идентификаторов
+func (bst *BusStation) Blocks(tt timetable.Timetable) []Block {
+	bst.mu.RLock()
+	ids := sortedIDs(bst.buses)
+	bst.mu.RUnlock()
+
+	blocks := make([]Block, 0, len(ids))
+	for _, id := range ids {
+		blocks = append(blocks, bst.Block(tt, id))
+	}
+	return blocks
+}
+
+// depotOf - точка депо автобуса. Депо, которого нет среди Depots станции,
+// известно только по идентификатору.
+func (bst *BusStation) depotOf(busID uuid.UUID) path.Point {
+	bst.mu.RLock()
+	defer bst.mu.RUnlock()
+	b, ok := bst.buses[busID]
+	if !ok {
+		return bst.Depot()
+	}
+	for _, d := range bst.depots {
+		if d.Id == b.Depot {
+			return d
+		}
+	}
+	return path.Point{Id: b.Depot, IsBusStation: true}
+}
+
+// AllocateDepots решает, какое депо обслуживает каждый блок: автобус
+// приписывается к тому депо станции, откуда выезд к первому пути блока
+// и возврат после последнего занимают меньше всего времени.
+// При равенстве автобус остается в своем депо. Автобусы без путей не трогаются.
+func (bst *BusStation) AllocateDepots(tt timetable.Timetable) {
+	if len(bst.depots) < 2 {
+		return
+	}
+	for _, blk := range bst.Blocks(tt) {
+		if len(blk.Paths) == 0 {
+			continue
+		}
+		first, last := blk.Paths[0], blk.Paths[len(blk.Paths)-1]
+		best, bestDur := blk.Depot.Id, time.Duration(-1)
+		if blk.Reachable {
+			bestDur = blk.Duration()
+		}
+		for _, d := range bst.depots {
+			out, okOut := tt.Deadhead(d.Id, first.Points[0].ID())
+			in, okIn := tt.Deadhead(last.Points[len(last.Points)-1].ID(), d.Id)
+			if !okOut || !okIn {
+				continue
+			}
+			dur := last.EndTime.Sub(first.StartTime) + out + in
+			if bestDur < 0 || dur < bestDur {
+				best, bestDur = d.Id, dur
+			}
+		}
+
+		bst.mu.Lock()
+		b := bst.buses[blk.BusID]
+		b.Depot = best
+		bst.buses[blk.BusID] = b
+		bst.mu.Unlock()
+	}
+}
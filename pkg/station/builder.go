@@ -2,14 +2,21 @@ package station
 
 import (
 	"course/pkg/bus"
+	"course/pkg/path"
+	"course/pkg/random"
 	"github.com/google/uuid"
 	"maps"
+	"slices"
 	"sync"
 )
 
 type BusStationBuilder struct {
-	mu    sync.Mutex
-	buses map[uuid.UUID]bus.Bus
+	mu sync.Mutex
+	// депо станции, за ним - остальные депо, к которым можно приписать ее автобусы
+	depots []path.Point
+	buses  map[uuid.UUID]bus.Bus
+	// из него выводятся генераторы идентификаторов собранных парков
+	ids *random.Source
 }
 
 func NewBusStationBuilder() *BusStationBuilder {
@@ -19,15 +26,59 @@ func NewBusStationBuilder() *BusStationBuilder {
 }
 
 func (builder *BusStationBuilder) Build() *BusStation {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+	seed := uint64(0)
+	if builder.ids != nil {
+		seed = builder.ids.Uint64()
+	}
 	station := BusStation{
-		buses: make(map[uuid.UUID]bus.Bus),
+		depots: slices.Clone(builder.depots),
+		buses:  make(map[uuid.UUID]bus.Bus),
+		ids:    random.New(seed),
 	}
 	maps.Copy(station.buses, builder.buses)
+	depot := station.Depot()
+	for id, b := range station.buses {
+		if b.Depot == uuid.Nil {
+			b.Depot = depot.ID()
+			station.buses[id] = b
+		}
+	}
 	return &station
 }
 
+// SetDepot задает депо станции - конечную точку, в которой ночуют ее автобусы
+func (builder *BusStationBuilder) SetDepot(depot path.Point) {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+	if len(builder.depots) == 0 {
+		builder.depots = append(builder.depots, depot)
+		return
+	}
+	builder.depots[0] = depot
+}
+
+// AddDepot добавляет депо, к которому AllocateDepots может приписать автобусы
+// станции. Первое добавленное депо становится депо станции, если оно не задано.
+func (builder *BusStationBuilder) AddDepot(depot path.Point) {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+	builder.depots = append(builder.depots, depot)
+}
+
 func (builder *BusStationBuilder) AddBus(bus *bus.Bus) {
 	builder.mu.Lock()
 	defer builder.mu.Unlock()
 	builder.buses[bus.ID] = *bus
 }
+
+// SetSeed задает зерно идентификаторов, которые выдают собранные парки.
+// Каждая следующая сборка получает свой генератор, выведенный из зерна,
+// поэтому при одном зерне и одном порядке сборок идентификаторы повторяются.
+// Без зерна идентификаторы случайные.
+func (builder *BusStationBuilder) SetSeed(seed uint64) {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+	builder.ids = random.New(random.Derive(seed, "buses"))
+}
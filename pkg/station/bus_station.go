@@ -3,19 +3,39 @@ package station
 import (
 	"course/pkg/bus"
 	"course/pkg/path"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/random"
+	"course/pkg/timetable"
 	"github.com/google/uuid"
 	"maps"
+	"slices"
+	"strings"
 	"sync"
-	"time"
 )
 
 type BusStation struct {
-	station path.Station
-	mu      sync.RWMutex
-	buses   map[uuid.UUID]bus.Bus
+	depots []path.Point
+	mu     sync.RWMutex
+	buses  map[uuid.UUID]bus.Bus
+	ids    *random.Source
 }
 
+// Depot - депо станции; без депо - нулевая точка
+func (bst *BusStation) Depot() path.Point {
+	if len(bst.depots) == 0 {
+		return path.Point{}
+	}
+	return bst.depots[0]
+}
+
+// Depots - все депо, к которым могут быть приписаны автобусы станции,
+// начиная с депо самой станции
+func (bst *BusStation) Depots() []path.Point {
+	return slices.Clone(bst.depots)
+}
+
+// NewID - идентификатор для нового автобуса этого парка
+func (bst *BusStation) NewID() uuid.UUID { return bst.ids.UUID() }
+
 func (bst *BusStation) Buses() map[uuid.UUID]bus.Bus {
 	bst.mu.Lock()
 	m := make(map[uuid.UUID]bus.Bus)
@@ -34,18 +54,33 @@ func (bst *BusStation) GetBus(id uuid.UUID) *bus.Bus {
 	return &b
 }
 
+// Register ставит автобус в парк; автобус без депо приписывается к депо станции
 func (bst *BusStation) Register(b *bus.Bus) {
 	bst.mu.Lock()
 	defer bst.mu.Unlock()
-	bst.buses[b.ID] = *b
+	nb := *b
+	if nb.Depot == uuid.Nil && len(bst.depots) > 0 {
+		nb.Depot = bst.depots[0].Id
+	}
+	bst.buses[b.ID] = nb
 }
 
+// Unregister списывает автобус
+func (bst *BusStation) Unregister(id uuid.UUID) {
+	bst.mu.Lock()
+	defer bst.mu.Unlock()
+	delete(bst.buses, id)
+}
+
+// GetNotInWork - автобус, который не занят в начале пути p и стоит в его
+// начальной точке или успевает приехать туда холостым перегоном
+// после своего предыдущего пути, а если путей у него еще нет - из депо
 func (bst *BusStation) GetNotInWork(
-	tt *ttv1.TimeTable,
-	timeTo time.Time,
+	tt timetable.Timetable,
+	p path.Path,
 ) *bus.Bus {
 	key := bst.getFirst(func(b bus.Bus) bool {
-		return !tt.BusOnTheWayToTime(timeTo, b.ID)
+		return !tt.BusOnTheWayToTime(p.StartTime, b.ID) && reachable(tt, p, b)
 	})
 	if key == uuid.Nil {
 		return nil
@@ -54,13 +89,35 @@ func (bst *BusStation) GetNotInWork(
 	return b
 }
 
+// reachable - успевает ли автобус от конца своего последнего пути,
+// закончившегося до начала p, к началу p. Автобус без таких путей
+// выезжает к p из депо, к началу дня время выезда не ограничено.
+func reachable(tt timetable.Timetable, p path.Path, b bus.Bus) bool {
+	if prev, found := tt.BusPathBefore(b.ID, p.StartTime); found {
+		return tt.Follows(prev, p)
+	}
+	_, ok := tt.Deadhead(b.Depot, p.Points[0].ID())
+	return b.Depot == uuid.Nil || ok
+}
+
 func (bst *BusStation) getFirst(fn func(b bus.Bus) bool) uuid.UUID {
 	bst.mu.RLock()
 	defer bst.mu.RUnlock()
-	for k, v := range bst.buses {
-		if fn(v) {
+	for _, k := range sortedIDs(bst.buses) {
+		if fn(bst.buses[k]) {
 			return k
 		}
 	}
 	return uuid.Nil
 }
+
+// sortedIDs - ключи в постоянном порядке, чтобы выбор автобуса не зависел
+// от порядка обхода map
+func sortedIDs(buses map[uuid.UUID]bus.Bus) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(buses))
+	for id := range buses {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
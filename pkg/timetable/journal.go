@@ -0,0 +1,83 @@
+package timetable
+
+import (
+	"errors"
+	"github.com/google/uuid"
+)
+
+var ErrNoTransaction = errors.New("no open transaction")
+
+// Change - смена водителя или автобуса пути
+type Change struct {
+	PathID uuid.UUID
+	// true - сменился автобус, false - водитель
+	Bus      bool
+	From, To uuid.UUID
+}
+
+// Journal - стек изменений назначений для транзакций расписания.
+// Изменения записываются, только пока открыта хотя бы одна транзакция;
+// Begin запоминает высоту стека, Rollback снимает изменения до нее,
+// Commit последней открытой транзакции очищает стек.
+// Journal не синхронизирован: его защищает блокировка расписания.
+type Journal struct {
+	changes []Change
+	marks   []int
+}
+
+// Begin открывает транзакцию, вложенную в уже открытые
+func (j *Journal) Begin() {
+	j.marks = append(j.marks, len(j.changes))
+}
+
+// Record запоминает изменение, если открыта транзакция
+func (j *Journal) Record(c Change) {
+	if len(j.marks) > 0 {
+		j.changes = append(j.changes, c)
+	}
+}
+
+// Commit закрывает последнюю транзакцию. Ее изменения остаются
+// во внешней транзакции и отменяются вместе с ней.
+func (j *Journal) Commit() error {
+	if len(j.marks) == 0 {
+		return ErrNoTransaction
+	}
+	j.marks = j.marks[:len(j.marks)-1]
+	if len(j.marks) == 0 {
+		j.changes = j.changes[:0]
+	}
+	return nil
+}
+
+// Rollback закрывает последнюю транзакцию и возвращает ее изменения
+// в порядке отмены - от последнего к первому
+func (j *Journal) Rollback() ([]Change, error) {
+	if len(j.marks) == 0 {
+		return nil, ErrNoTransaction
+	}
+	m := j.marks[len(j.marks)-1]
+	j.marks = j.marks[:len(j.marks)-1]
+	res := make([]Change, 0, len(j.changes)-m)
+	for i := len(j.changes) - 1; i >= m; i-- {
+		res = append(res, j.changes[i])
+	}
+	j.changes = j.changes[:m]
+	return res, nil
+}
+
+// Undo снимает последнее изменение последней транзакции;
+// ok == false, если в ней нечего отменять
+func (j *Journal) Undo() (c Change, ok bool) {
+	if len(j.marks) == 0 || len(j.changes) == j.marks[len(j.marks)-1] {
+		return Change{}, false
+	}
+	c = j.changes[len(j.changes)-1]
+	j.changes = j.changes[:len(j.changes)-1]
+	return c, true
+}
+
+// Depth - число открытых транзакций
+func (j *Journal) Depth() int {
+	return len(j.marks)
+}
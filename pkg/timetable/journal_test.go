@@ -0,0 +1,97 @@
+package timetable
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+)
+
+// change - смена водителя пути номер n
+func change(n int) Change { return Change{PathID: uuid.UUID{byte(n)}} }
+
+// op - шаг сценария журнала
+type op struct {
+	name string
+	n    int
+}
+
+func TestJournal(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []op
+		// номера изменений, которые вернул последний Rollback, в его порядке
+		rolledBack []int
+		depth      int
+		// сколько изменений осталось в журнале
+		left int
+	}{
+		{"outside a transaction nothing is recorded", []op{{"record", 1}, {"begin", 0}, {"rollback", 0}}, []int{}, 0, 0},
+		{"rollback in reverse order", []op{{"begin", 0}, {"record", 1}, {"record", 2}, {"record", 3}, {"rollback", 0}}, []int{3, 2, 1}, 0, 0},
+		{"commit of the last transaction clears", []op{{"begin", 0}, {"record", 1}, {"commit", 0}}, nil, 0, 0},
+		{"nested rollback keeps the outer changes", []op{{"begin", 0}, {"record", 1}, {"begin", 0}, {"record", 2}, {"rollback", 0}}, []int{2}, 1, 1},
+		{"nested commit is undone with the outer", []op{{"begin", 0}, {"record", 1}, {"begin", 0}, {"record", 2}, {"commit", 0}, {"rollback", 0}}, []int{2, 1}, 0, 0},
+		{"undo drops the last change", []op{{"begin", 0}, {"record", 1}, {"record", 2}, {"undo", 2}, {"rollback", 0}}, []int{1}, 0, 0},
+		{"undo stops at the transaction", []op{{"begin", 0}, {"record", 1}, {"begin", 0}, {"undo", 0}}, nil, 2, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				j          Journal
+				rolledBack []int
+			)
+			for _, o := range tc.ops {
+				switch o.name {
+				case "begin":
+					j.Begin()
+				case "record":
+					j.Record(change(o.n))
+				case "commit":
+					if err := j.Commit(); err != nil {
+						t.Fatalf("Commit = %v", err)
+					}
+				case "rollback":
+					cs, err := j.Rollback()
+					if err != nil {
+						t.Fatalf("Rollback = %v", err)
+					}
+					rolledBack = []int{}
+					for _, c := range cs {
+						rolledBack = append(rolledBack, int(c.PathID[0]))
+					}
+				case "undo":
+					// o.n == 0 - отменять нечего
+					got := 0
+					if c, ok := j.Undo(); ok {
+						got = int(c.PathID[0])
+					}
+					if got != o.n {
+						t.Fatalf("Undo = change %d, want %d", got, o.n)
+					}
+				}
+			}
+			if !slices.Equal(rolledBack, tc.rolledBack) {
+				t.Errorf("Rollback returned %v, want %v", rolledBack, tc.rolledBack)
+			}
+			if j.Depth() != tc.depth {
+				t.Errorf("Depth = %d, want %d", j.Depth(), tc.depth)
+			}
+			if len(j.changes) != tc.left {
+				t.Errorf("%d changes left, want %d", len(j.changes), tc.left)
+			}
+		})
+	}
+}
+
+func TestJournalNoTransaction(t *testing.T) {
+	var j Journal
+	if err := j.Commit(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Commit = %v, want ErrNoTransaction", err)
+	}
+	if _, err := j.Rollback(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Rollback = %v, want ErrNoTransaction", err)
+	}
+	if _, ok := j.Undo(); ok {
+		t.Errorf("Undo without a transaction returned a change")
+	}
+}
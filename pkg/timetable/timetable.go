@@ -0,0 +1,99 @@
+package timetable
+
+import (
+	"bytes"
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Timetable - расписание путей с назначенными водителями и автобусами.
+// Все обходы идут в постоянном порядке PathIDs, чтобы результат
+// не зависел от порядка обхода map. Реализации безопасны
+// для одновременного вызова.
+type Timetable interface {
+	// Paths - все пути по идентификатору
+	Paths() map[uuid.UUID]path.Path
+	// PathIDs - идентификаторы всех путей в порядке возрастания
+	PathIDs() []uuid.UUID
+	PathsLen() int
+	// GetPathByID - путь по идентификатору; нулевой путь, если его нет
+	GetPathByID(pathID uuid.UUID) path.Path
+	// GetEach - все пути, для которых fn возвращает true
+	GetEach(fn func(p path.Path) bool) map[uuid.UUID]path.Path
+	// GetFirstN - первые в порядке PathIDs n путей, для которых fn возвращает true
+	GetFirstN(n int, fn func(p path.Path) bool) []uuid.UUID
+	// LastBefore - путь из отобранных fn, закончившийся не позже at позже всех;
+	// при равном конце - первый из них в порядке PathIDs. ok == false, если таких нет.
+	LastBefore(at time.Time, fn func(p path.Path) bool) (path.Path, bool)
+	// GetPathToTime - свободный путь, начинающийся в течение получаса после timeTo;
+	// uuid.Nil, если такого нет
+	GetPathToTime(timeTo time.Time) uuid.UUID
+	// NextPathAfter - ближайший путь, начинающийся позже t; при равном начале -
+	// с меньшим идентификатором. ok == false, если таких путей нет.
+	NextPathAfter(t time.Time) (p path.Path, ok bool)
+	// PathsByStart - все пути в порядке ComparePaths
+	PathsByStart() []path.Path
+	// DriverPaths - пути водителя по времени начала, при равном начале - по идентификатору
+	DriverPaths(driverID uuid.UUID) []path.Path
+	// BusPaths - пути автобуса по времени начала, при равном начале - по идентификатору
+	BusPaths(busID uuid.UUID) []path.Path
+	// DriverPathBefore - LastBefore по путям водителя
+	DriverPathBefore(driverID uuid.UUID, t time.Time) (path.Path, bool)
+	// BusPathBefore - LastBefore по путям автобуса
+	BusPathBefore(busID uuid.UUID, t time.Time) (path.Path, bool)
+	// BusOnTheWayToTime - выполняет ли автобус путь строго внутри момента timeTo
+	BusOnTheWayToTime(timeTo time.Time, busID uuid.UUID) bool
+	// DriverOnTheWayToTime - выполняет ли водитель путь строго внутри момента timeTo
+	DriverOnTheWayToTime(timeTo time.Time, driverID uuid.UUID) bool
+
+	AssignDriverToPath(pathID uuid.UUID, driverID uuid.UUID)
+	AssignBusToPath(pathID uuid.UUID, busID uuid.UUID)
+	// RemovePath убирает путь из расписания, например при отмене рейса.
+	// Транзакции его не отменяют.
+	RemovePath(pathID uuid.UUID)
+
+	// Begin открывает транзакцию назначений; транзакции могут быть вложенными.
+	// Пока транзакция открыта, смены водителей и автобусов путей
+	// запоминаются, и их можно отменить без копии расписания.
+	Begin()
+	// Commit закрывает последнюю транзакцию, оставляя ее изменения;
+	// ErrNoTransaction, если открытых транзакций нет
+	Commit() error
+	// Rollback закрывает последнюю транзакцию, отменяя ее изменения;
+	// ErrNoTransaction, если открытых транзакций нет
+	Rollback() error
+	// Undo отменяет последнее изменение назначения в последней транзакции;
+	// false, если отменять нечего
+	Undo() bool
+
+	// Deadhead - минимальное время холостого перегона из точки from в точку to.
+	// ok == false, если пути между точками нет.
+	Deadhead(from, to uuid.UUID) (time.Duration, bool)
+	// Follows - может ли автобус после пути a успеть перегоном к началу пути b
+	Follows(a, b path.Path) bool
+	// DeadheadMoves - холостые перегоны из текущего назначения автобусов,
+	// по автобусам, а у автобуса - по времени
+	DeadheadMoves() []DeadheadMove
+}
+
+// DeadheadMove - холостой перегон автобуса между двумя его путями
+type DeadheadMove struct {
+	BusID  uuid.UUID
+	From   path.Point
+	To     path.Point
+	Depart time.Time
+	Arrive time.Time
+	// путь, к которому едет автобус
+	NextPathID uuid.UUID
+}
+
+// ComparePaths - порядок путей по времени начала, при равном начале -
+// по идентификатору. В этом порядке реализации отдают пути водителя и автобуса.
+func ComparePaths(a, b path.Path) int {
+	if c := a.StartTime.Compare(b.StartTime); c != 0 {
+		return c
+	}
+	// байты UUID сравниваются так же, как его строки, но без выделения памяти
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
@@ -0,0 +1,41 @@
+package timetabletest
+
+import (
+	"course/pkg/path"
+	"course/pkg/random"
+	"course/pkg/timetable/ttv1"
+	"fmt"
+	"time"
+)
+
+// Synthetic - расписание из n путей по 50 маршрутам между 20 конечными,
+// с началом с 5 до 23 часов. Одно зерно seed дает одно расписание.
+func Synthetic(seed uint64, n int) *ttv1.TimetableBuilder {
+	const (
+		terminals = 20
+		routes    = 50
+	)
+	rnd := random.New(seed)
+	points := make([]path.Point, terminals)
+	for i := range points {
+		points[i] = path.Point{Id: rnd.UUID(), Name: fmt.Sprintf("Terminal%d", i+1), IsBusStation: true}
+	}
+	day := time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC)
+
+	ttb := ttv1.NewBuilder()
+	for r := 0; r < routes; r++ {
+		p := path.NewPath(points[rnd.IntN(terminals)], points[rnd.IntN(terminals)], r+1, rnd.IntN(10)+10, day, rnd)
+		items := p.GenDstItems(rnd)
+		var ride time.Duration
+		for _, it := range items {
+			ride += it.Dur
+		}
+		for i := r; i < n; i += routes {
+			p.ID = rnd.UUID()
+			p.StartTime = day.Add(5*time.Hour + time.Duration(rnd.IntN(18*60))*time.Minute)
+			p.EndTime = p.StartTime.Add(ride)
+			ttb.AddPath(p, items)
+		}
+	}
+	return ttb
+}
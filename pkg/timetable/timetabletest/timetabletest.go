@@ -0,0 +1,532 @@
+package timetabletest
+
+import (
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"errors"
+	"github.com/google/uuid"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run проверяет, что реализация timetable.Timetable ведет себя как положено.
+// Каждая проверка - отдельный подтест со своим расписанием из newTT.
+// newTT должна строить свежее расписание: не меньше двух путей,
+// ни одного назначения. Проверки меняют назначения и удаляют пути.
+func Run(t *testing.T, newTT func() timetable.Timetable) {
+	tests := []struct {
+		name string
+		run  func(c *checker)
+	}{
+		{"Paths", (*checker).testPaths},
+		{"Queries", (*checker).testQueries},
+		{"NextPathAfter", (*checker).testNextPath},
+		{"Assign", (*checker).testAssign},
+		{"OwnerPaths", (*checker).testOwnerPaths},
+		{"Transactions", (*checker).testTransactions},
+		{"Deadhead", (*checker).testDeadhead},
+		{"DeadheadMoves", (*checker).testDeadheadMoves},
+		{"RemovePath", (*checker).testRemove},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTT()
+			if tt.PathsLen() < 2 {
+				t.Fatalf("timetable has %d paths, need at least 2", tt.PathsLen())
+			}
+			tc.run(&checker{t: t, tt: tt})
+		})
+	}
+}
+
+type checker struct {
+	t  *testing.T
+	tt timetable.Timetable
+}
+
+func (c *checker) errorf(format string, args ...any) {
+	c.t.Helper()
+	c.t.Errorf(format, args...)
+}
+
+func all(path.Path) bool { return true }
+
+// testPaths - PathIDs, Paths, PathsLen и GetPathByID согласованы,
+// а PathIDs и Paths не отдают внутреннее состояние
+func (c *checker) testPaths() {
+	ids := c.tt.PathIDs()
+	paths := c.tt.Paths()
+	if n := c.tt.PathsLen(); len(ids) != n || len(paths) != n {
+		c.errorf("PathsLen = %d, len(PathIDs) = %d, len(Paths) = %d", n, len(ids), len(paths))
+	}
+	for i, id := range ids {
+		if i > 0 && strings.Compare(ids[i-1].String(), id.String()) >= 0 {
+			c.errorf("PathIDs not strictly ascending at %d: %s, %s", i, ids[i-1], id)
+		}
+		p, ok := paths[id]
+		if !ok {
+			c.errorf("PathIDs has %s, Paths does not", id)
+			continue
+		}
+		if p.ID != id {
+			c.errorf("Paths[%s].ID = %s", id, p.ID)
+		}
+		if got := c.tt.GetPathByID(id); got.ID != id {
+			c.errorf("GetPathByID(%s).ID = %s", id, got.ID)
+		}
+		if p.DriverID != uuid.Nil || p.BusID != uuid.Nil {
+			c.errorf("path %s is already assigned", id)
+		}
+	}
+	if got := c.tt.GetPathByID(unknownID(ids)); got.ID != uuid.Nil {
+		c.errorf("GetPathByID of an unknown id returned path %s", got.ID)
+	}
+
+	// PathIDs и Paths отдают копии: их изменение не трогает расписание
+	ids[0], ids[1] = ids[1], ids[0]
+	clear(paths)
+	if got := c.tt.PathIDs(); got[0] != ids[1] || got[1] != ids[0] {
+		c.errorf("changing the slice returned by PathIDs changed the timetable")
+	}
+	if got := c.tt.Paths(); len(got) != len(ids) {
+		c.errorf("clearing the map returned by Paths left %d paths, want %d", len(got), len(ids))
+	}
+}
+
+// testQueries - GetEach, GetFirstN, LastBefore и GetPathToTime отбирают пути по условию
+// в порядке PathIDs
+func (c *checker) testQueries() {
+	ids := c.tt.PathIDs()
+	if got := c.tt.GetEach(all); !maps.EqualFunc(got, c.tt.Paths(), samePath) {
+		c.errorf("GetEach(all) differs from Paths")
+	}
+	if got := c.tt.GetEach(func(path.Path) bool { return false }); len(got) != 0 {
+		c.errorf("GetEach(none) returned %d paths", len(got))
+	}
+
+	first := ids[0]
+	got := c.tt.GetEach(func(p path.Path) bool { return p.ID == first })
+	if len(got) != 1 || got[first].ID != first {
+		c.errorf("GetEach(id == %s) returned %d paths", first, len(got))
+	}
+
+	if got := c.tt.PathsByStart(); !slices.EqualFunc(got, c.sorted(), samePath) {
+		c.errorf("PathsByStart returned %d paths, want all %d in start order", len(got), len(ids))
+	}
+
+	if got := c.tt.GetFirstN(2, all); !slices.Equal(got, ids[:2]) {
+		c.errorf("GetFirstN(2, all) = %v, want %v", got, ids[:2])
+	}
+	odd := func(p path.Path) bool { return p.Number%2 == 1 }
+	var want []uuid.UUID
+	for _, id := range ids {
+		if odd(c.tt.GetPathByID(id)) {
+			want = append(want, id)
+		}
+	}
+	if got := c.tt.GetFirstN(len(ids), odd); !slices.Equal(got, want) {
+		c.errorf("GetFirstN(all, odd number) = %v, want %v", got, want)
+	}
+
+	for _, p := range c.sorted() {
+		var want path.Path
+		for _, id := range ids {
+			if o := c.tt.GetPathByID(id); !o.EndTime.After(p.StartTime) && odd(o) &&
+				(want.ID == uuid.Nil || o.EndTime.After(want.EndTime)) {
+				want = o
+			}
+		}
+		if got, ok := c.tt.LastBefore(p.StartTime, odd); got.ID != want.ID || ok != (want.ID != uuid.Nil) {
+			c.errorf("LastBefore(start of %d, odd number) = %s, %v, want %s", p.Number, got.ID, ok, want.ID)
+		}
+	}
+	if got, ok := c.tt.LastBefore(time.Time{}, all); ok {
+		c.errorf("LastBefore(zero time) returned path %s", got.ID)
+	}
+
+	p := c.tt.GetPathByID(first)
+	id := c.tt.GetPathToTime(p.StartTime.Add(-time.Minute))
+	if id == uuid.Nil {
+		c.errorf("GetPathToTime a minute before free path %s found nothing", first)
+	} else if q := c.tt.GetPathByID(id); !q.StartTime.After(p.StartTime.Add(-time.Minute)) ||
+		!q.StartTime.Before(p.StartTime.Add(29*time.Minute)) {
+		c.errorf("GetPathToTime returned path %s starting at %s, outside the half hour", id, q.StartTime)
+	}
+}
+
+// testNextPath - NextPathAfter возвращает следующий по началу путь
+func (c *checker) testNextPath() {
+	ps := c.sorted()
+	if got, ok := c.tt.NextPathAfter(ps[0].StartTime.Add(-time.Second)); !ok || got.ID != ps[0].ID {
+		c.errorf("NextPathAfter before the first path = %s, %v, want %s", got.ID, ok, ps[0].ID)
+	}
+	for i, p := range ps {
+		j := i + 1
+		for j < len(ps) && !ps[j].StartTime.After(p.StartTime) {
+			j++
+		}
+		got, ok := c.tt.NextPathAfter(p.StartTime)
+		if j == len(ps) {
+			if ok {
+				c.errorf("NextPathAfter the last start returned path %s", got.ID)
+			}
+			continue
+		}
+		if !ok || got.ID != ps[j].ID {
+			c.errorf("NextPathAfter(%s) = %s, %v, want %s", p.StartTime, got.ID, ok, ps[j].ID)
+		}
+	}
+}
+
+// testAssign - назначения видны во всех запросах и снимаются назначением uuid.Nil
+func (c *checker) testAssign() {
+	id := c.tt.PathIDs()[0]
+	p := c.tt.GetPathByID(id)
+	drv, bus := uuid.New(), uuid.New()
+
+	c.tt.AssignDriverToPath(id, drv)
+	c.tt.AssignBusToPath(id, bus)
+	got := c.tt.GetPathByID(id)
+	if got.DriverID != drv || got.BusID != bus {
+		c.errorf("after assign path %s has driver %s, bus %s", id, got.DriverID, got.BusID)
+	}
+	if got.StartTime != p.StartTime || got.EndTime != p.EndTime || got.Number != p.Number {
+		c.errorf("assign changed path %s beyond driver and bus", id)
+	}
+	if ps := c.tt.GetEach(func(p path.Path) bool { return p.DriverID == drv }); len(ps) != 1 {
+		c.errorf("GetEach(driver) after assign returned %d paths", len(ps))
+	}
+	if got := c.tt.DriverPaths(drv); len(got) != 1 || got[0].ID != id {
+		c.errorf("DriverPaths after assign returned %d paths", len(got))
+	}
+	if c.tt.Paths()[id].BusID != bus {
+		c.errorf("Paths does not see the bus assigned to %s", id)
+	}
+
+	mid := p.StartTime.Add(p.EndTime.Sub(p.StartTime) / 2)
+	if p.EndTime.After(p.StartTime) {
+		if !c.tt.DriverOnTheWayToTime(mid, drv) || !c.tt.BusOnTheWayToTime(mid, bus) {
+			c.errorf("driver and bus of path %s are not on the way in the middle of it", id)
+		}
+	}
+	if c.tt.DriverOnTheWayToTime(p.StartTime, drv) || c.tt.BusOnTheWayToTime(p.EndTime, bus) {
+		c.errorf("driver and bus of path %s are on the way at its start or end", id)
+	}
+	if c.tt.GetPathToTime(p.StartTime.Add(-time.Second)) == id {
+		c.errorf("GetPathToTime returned assigned path %s", id)
+	}
+
+	c.tt.AssignDriverToPath(id, uuid.Nil)
+	c.tt.AssignBusToPath(id, uuid.Nil)
+	if got := c.tt.GetPathByID(id); got.DriverID != uuid.Nil || got.BusID != uuid.Nil {
+		c.errorf("assigning uuid.Nil did not clear path %s", id)
+	}
+	if c.tt.DriverOnTheWayToTime(mid, drv) {
+		c.errorf("driver is on the way after unassign from %s", id)
+	}
+}
+
+// testOwnerPaths - DriverPaths и BusPaths отдают пути по началу
+// и согласованы с занятостью водителя и автобуса
+func (c *checker) testOwnerPaths() {
+	ps := c.sorted()
+	// каждый третий путь, с конца, чтобы порядок назначения не совпадал с началом
+	var mine []path.Path
+	drv, bus := uuid.New(), uuid.New()
+	for i := len(ps) - 1; i >= 0; i -= 3 {
+		c.tt.AssignDriverToPath(ps[i].ID, drv)
+		c.tt.AssignBusToPath(ps[i].ID, bus)
+		mine = append(mine, c.tt.GetPathByID(ps[i].ID))
+	}
+	slices.SortFunc(mine, timetable.ComparePaths)
+
+	if got := c.tt.DriverPaths(drv); !slices.EqualFunc(got, mine, samePath) {
+		c.errorf("DriverPaths returned %d paths, want %d in start order", len(got), len(mine))
+	}
+	if got := c.tt.BusPaths(bus); !slices.EqualFunc(got, mine, samePath) {
+		c.errorf("BusPaths returned %d paths, want %d in start order", len(got), len(mine))
+	}
+	for _, p := range ps {
+		want, wantOK := lastBefore(mine, p.StartTime)
+		if got, ok := c.tt.DriverPathBefore(drv, p.StartTime); ok != wantOK || got.ID != want.ID {
+			c.errorf("DriverPathBefore(%s) = %s, %v, want %s, %v", p.StartTime, got.ID, ok, want.ID, wantOK)
+		}
+		if got, ok := c.tt.BusPathBefore(bus, p.StartTime); ok != wantOK || got.ID != want.ID {
+			c.errorf("BusPathBefore(%s) = %s, %v, want %s, %v", p.StartTime, got.ID, ok, want.ID, wantOK)
+		}
+	}
+	for _, p := range ps {
+		mid := p.StartTime.Add(p.EndTime.Sub(p.StartTime) / 2)
+		want := false
+		for _, m := range mine {
+			if mid.After(m.StartTime) && mid.Before(m.EndTime) {
+				want = true
+			}
+		}
+		if got := c.tt.DriverOnTheWayToTime(mid, drv); got != want {
+			c.errorf("DriverOnTheWayToTime(%s) = %v, want %v", mid, got, want)
+		}
+		if got := c.tt.BusOnTheWayToTime(mid, bus); got != want {
+			c.errorf("BusOnTheWayToTime(%s) = %v, want %v", mid, got, want)
+		}
+	}
+
+	// переназначение переносит путь между водителями, а снятие убирает его
+	other := uuid.New()
+	c.tt.AssignDriverToPath(mine[0].ID, other)
+	if got := c.tt.DriverPaths(other); len(got) != 1 || got[0].ID != mine[0].ID {
+		c.errorf("DriverPaths after reassign returned %d paths", len(got))
+	}
+	if got := c.tt.DriverPaths(drv); len(got) != len(mine)-1 {
+		c.errorf("DriverPaths of the previous driver has %d paths, want %d", len(got), len(mine)-1)
+	}
+	if got := c.tt.BusPaths(bus); len(got) != len(mine) || got[0].DriverID != other {
+		c.errorf("BusPaths does not see the driver reassigned on path %s", mine[0].ID)
+	}
+	for _, m := range mine {
+		c.tt.AssignDriverToPath(m.ID, uuid.Nil)
+		c.tt.AssignBusToPath(m.ID, uuid.Nil)
+	}
+	if len(c.tt.DriverPaths(drv)) != 0 || len(c.tt.DriverPaths(other)) != 0 || len(c.tt.BusPaths(bus)) != 0 {
+		c.errorf("DriverPaths or BusPaths not empty after unassign")
+	}
+
+	// все пути одному водителю: пути пересекаются и вложены друг в друга,
+	// занятость и последний путь сверяются с перебором всех путей
+	for _, p := range ps {
+		c.tt.AssignDriverToPath(p.ID, drv)
+	}
+	for _, p := range ps {
+		for _, at := range []time.Time{p.EndTime, p.EndTime.Add(time.Minute), p.StartTime.Add(time.Minute)} {
+			want := false
+			for _, o := range ps {
+				if at.After(o.StartTime) && at.Before(o.EndTime) {
+					want = true
+					break
+				}
+			}
+			if got := c.tt.DriverOnTheWayToTime(at, drv); got != want {
+				c.errorf("DriverOnTheWayToTime(%s) with all paths = %v, want %v", at, got, want)
+			}
+			last, lastOK := lastBefore(ps, at)
+			if got, ok := c.tt.DriverPathBefore(drv, at); ok != lastOK || got.ID != last.ID {
+				c.errorf("DriverPathBefore(%s) with all paths = %s, %v, want %s, %v", at, got.ID, ok, last.ID, lastOK)
+			}
+		}
+	}
+	for _, p := range ps {
+		c.tt.AssignDriverToPath(p.ID, uuid.Nil)
+	}
+}
+
+// testTransactions - Rollback и Undo возвращают назначения и индексы,
+// изменения вне транзакций и закрытых транзакций не отменяются
+func (c *checker) testTransactions() {
+	if err := c.tt.Commit(); !errors.Is(err, timetable.ErrNoTransaction) {
+		c.errorf("Commit without Begin = %v", err)
+	}
+	if err := c.tt.Rollback(); !errors.Is(err, timetable.ErrNoTransaction) {
+		c.errorf("Rollback without Begin = %v", err)
+	}
+	if c.tt.Undo() {
+		c.errorf("Undo without Begin returned true")
+	}
+
+	ps := c.sorted()
+	a, b := ps[0], ps[len(ps)-1]
+	before, other, bus := uuid.New(), uuid.New(), uuid.New()
+	c.tt.AssignDriverToPath(a.ID, before)
+
+	c.tt.Begin()
+	c.tt.AssignDriverToPath(a.ID, other)
+	c.tt.AssignBusToPath(b.ID, bus)
+	c.tt.AssignDriverToPath(b.ID, other)
+	c.tt.AssignDriverToPath(b.ID, other)
+	if err := c.tt.Rollback(); err != nil {
+		c.errorf("Rollback = %v", err)
+	}
+	if got := c.tt.GetPathByID(a.ID); got.DriverID != before {
+		c.errorf("after Rollback path %s has driver %s, want the one assigned before Begin", a.ID, got.DriverID)
+	}
+	if got := c.tt.GetPathByID(b.ID); got.DriverID != uuid.Nil || got.BusID != uuid.Nil {
+		c.errorf("after Rollback path %s has driver %s, bus %s", b.ID, got.DriverID, got.BusID)
+	}
+	if len(c.tt.DriverPaths(other)) != 0 || len(c.tt.BusPaths(bus)) != 0 || len(c.tt.DriverPaths(before)) != 1 {
+		c.errorf("DriverPaths or BusPaths not restored by Rollback")
+	}
+	if mid := b.StartTime.Add(b.EndTime.Sub(b.StartTime) / 2); c.tt.DriverOnTheWayToTime(mid, other) {
+		c.errorf("driver is on the way after Rollback of path %s", b.ID)
+	}
+
+	// вложенная транзакция после Commit отменяется вместе с внешней
+	c.tt.Begin()
+	c.tt.AssignDriverToPath(a.ID, other)
+	c.tt.Begin()
+	c.tt.AssignDriverToPath(b.ID, other)
+	if err := c.tt.Commit(); err != nil {
+		c.errorf("Commit of a nested transaction = %v", err)
+	}
+	if got := c.tt.GetPathByID(b.ID); got.DriverID != other {
+		c.errorf("Commit of a nested transaction lost its change")
+	}
+	c.tt.Rollback()
+	if c.tt.GetPathByID(a.ID).DriverID != before || c.tt.GetPathByID(b.ID).DriverID != uuid.Nil {
+		c.errorf("Rollback did not undo a committed nested transaction")
+	}
+
+	// Undo снимает изменения по одному с конца, Commit оставляет остальные
+	c.tt.Begin()
+	c.tt.AssignDriverToPath(a.ID, other)
+	c.tt.AssignBusToPath(b.ID, bus)
+	if !c.tt.Undo() || c.tt.GetPathByID(b.ID).BusID != uuid.Nil || c.tt.GetPathByID(a.ID).DriverID != other {
+		c.errorf("Undo did not revert only the last change")
+	}
+	if len(c.tt.BusPaths(bus)) != 0 {
+		c.errorf("BusPaths not restored by Undo")
+	}
+	c.tt.Commit()
+	if c.tt.GetPathByID(a.ID).DriverID != other || c.tt.Undo() {
+		c.errorf("Commit did not keep the changes or left them undoable")
+	}
+	c.tt.AssignDriverToPath(a.ID, uuid.Nil)
+}
+
+// testDeadhead - перегоны и Follows согласованы друг с другом
+func (c *checker) testDeadhead() {
+	ps := c.sorted()
+	for _, p := range ps {
+		from := p.Points[0].ID()
+		if d, ok := c.tt.Deadhead(from, from); !ok || d != 0 {
+			c.errorf("Deadhead from a point to itself = %s, %v", d, ok)
+		}
+	}
+	for i, a := range ps {
+		for _, b := range ps[i+1:] {
+			dh, ok := c.tt.Deadhead(a.Last().ID(), b.Points[0].ID())
+			want := ok && !b.StartTime.Before(a.EndTime) && !a.EndTime.Add(dh).After(b.StartTime)
+			if got := c.tt.Follows(a, b); got != want {
+				c.errorf("Follows(%d, %d) = %v, deadhead %s, %v", a.Number, b.Number, got, dh, ok)
+			}
+		}
+	}
+}
+
+// testDeadheadMoves - перегон есть ровно между последовательными путями
+// одного автобуса, которые начинаются не там, где закончился предыдущий
+func (c *checker) testDeadheadMoves() {
+	if len(c.tt.DeadheadMoves()) != 0 {
+		c.errorf("DeadheadMoves without buses is not empty")
+	}
+	ps := c.sorted()
+	a, b, ok := c.followingPair(ps)
+	if !ok {
+		return
+	}
+	bus := uuid.New()
+	c.tt.AssignBusToPath(a.ID, bus)
+	c.tt.AssignBusToPath(b.ID, bus)
+	defer c.tt.AssignBusToPath(a.ID, uuid.Nil)
+	defer c.tt.AssignBusToPath(b.ID, uuid.Nil)
+
+	moves := c.tt.DeadheadMoves()
+	if a.Last().ID() == b.Points[0].ID() {
+		if len(moves) != 0 {
+			c.errorf("DeadheadMoves between paths %d and %d at one point = %d", a.Number, b.Number, len(moves))
+		}
+		return
+	}
+	if len(moves) != 1 {
+		c.errorf("DeadheadMoves for one bus on paths %d, %d = %d moves, want 1", a.Number, b.Number, len(moves))
+		return
+	}
+	m := moves[0]
+	dh, _ := c.tt.Deadhead(a.Last().ID(), b.Points[0].ID())
+	if m.BusID != bus || m.NextPathID != b.ID || !m.Depart.Equal(a.EndTime) || !m.Arrive.Equal(a.EndTime.Add(dh)) ||
+		m.From.ID() != a.Last().ID() || m.To.ID() != b.Points[0].ID() {
+		c.errorf("DeadheadMoves = %+v for paths %d, %d", m, a.Number, b.Number)
+	}
+}
+
+// testRemove - удаленный путь пропадает из всех запросов
+func (c *checker) testRemove() {
+	ids := c.tt.PathIDs()
+	id := ids[0]
+	n := c.tt.PathsLen()
+	c.tt.RemovePath(id)
+	if c.tt.PathsLen() != n-1 || len(c.tt.Paths()) != n-1 || len(c.tt.PathIDs()) != n-1 {
+		c.errorf("after RemovePath PathsLen = %d, want %d", c.tt.PathsLen(), n-1)
+	}
+	if slices.Contains(c.tt.PathIDs(), id) {
+		c.errorf("PathIDs still has removed path %s", id)
+	}
+	if got := c.tt.GetPathByID(id); got.ID != uuid.Nil {
+		c.errorf("GetPathByID returned removed path %s", id)
+	}
+	if got, ok := c.tt.NextPathAfter(time.Time{}); ok && got.ID == id {
+		c.errorf("NextPathAfter returned removed path %s", id)
+	}
+	if len(c.tt.GetEach(func(p path.Path) bool { return p.ID == id })) != 0 {
+		c.errorf("GetEach returned removed path %s", id)
+	}
+	if ids[0] != id || len(ids) != n {
+		c.errorf("RemovePath changed a PathIDs slice returned before it")
+	}
+}
+
+// followingPair - первая по времени пара путей, где второй может идти за первым
+func (c *checker) followingPair(ps []path.Path) (path.Path, path.Path, bool) {
+	for i, a := range ps {
+		for _, b := range ps[i+1:] {
+			if c.tt.Follows(a, b) {
+				return a, b, true
+			}
+		}
+	}
+	return path.Path{}, path.Path{}, false
+}
+
+func (c *checker) sorted() []path.Path {
+	var ps []path.Path
+	for _, p := range c.tt.GetEach(all) {
+		ps = append(ps, p)
+	}
+	slices.SortFunc(ps, func(a, b path.Path) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return ps
+}
+
+// lastBefore - LastBefore перебором ps: самый поздний конец не позже t,
+// при равном конце - меньший идентификатор, то есть раньше в PathIDs
+func lastBefore(ps []path.Path, t time.Time) (p path.Path, ok bool) {
+	for _, o := range ps {
+		if o.EndTime.After(t) {
+			continue
+		}
+		if !ok || o.EndTime.After(p.EndTime) ||
+			o.EndTime.Equal(p.EndTime) && strings.Compare(o.ID.String(), p.ID.String()) < 0 {
+			p, ok = o, true
+		}
+	}
+	return p, ok
+}
+
+func samePath(a, b path.Path) bool {
+	return a.ID == b.ID && a.Number == b.Number && a.DriverID == b.DriverID && a.BusID == b.BusID &&
+		a.StartTime.Equal(b.StartTime) && a.EndTime.Equal(b.EndTime)
+}
+
+func unknownID(ids []uuid.UUID) uuid.UUID {
+	for {
+		id := uuid.New()
+		if !slices.Contains(ids, id) {
+			return id
+		}
+	}
+}
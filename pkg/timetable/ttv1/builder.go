@@ -4,6 +4,8 @@ import (
 	"course/pkg/path"
 	"github.com/google/uuid"
 	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,6 +40,13 @@ func (builder *TimetableBuilder) Build() *TimeTable {
 	maps.Copy(t.stations, builder.stations)
 	maps.Copy(t.paths, builder.paths)
 	maps.Copy(t.stationsDistances, builder.stationsDistances)
+	t.order = make([]uuid.UUID, 0, len(t.paths))
+	for id := range t.paths {
+		t.order = append(t.order, id)
+	}
+	slices.SortFunc(t.order, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
 	return &t
 }
 
@@ -0,0 +1,12 @@
+package ttv1_test
+
+import (
+	"course/pkg/timetable"
+	"course/pkg/timetable/timetabletest"
+	"testing"
+)
+
+func TestTimetable(t *testing.T) {
+	ttb := timetabletest.Synthetic(1, 200)
+	timetabletest.Run(t, func() timetable.Timetable { return ttb.Build() })
+}
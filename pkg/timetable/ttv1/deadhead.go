@@ -0,0 +1,129 @@
+package ttv1
+
+import (
+	"container/heap"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Deadhead - минимальное время холостого перегона из точки from в точку to
+// по графу расстояний между остановками. ok == false, если пути между точками нет.
+func (t *TimeTable) Deadhead(from, to uuid.UUID) (time.Duration, bool) {
+	if from == to {
+		return 0, true
+	}
+
+	t.dhMu.Lock()
+	defer t.dhMu.Unlock()
+	if t.deadheads == nil {
+		t.deadheads = make(map[uuid.UUID]map[uuid.UUID]time.Duration)
+	}
+	dst, ok := t.deadheads[from]
+	if !ok {
+		dst = t.shortestFrom(from)
+		t.deadheads[from] = dst
+	}
+	d, ok := dst[to]
+	return d, ok
+}
+
+// Follows - может ли автобус после пути a выполнить путь b: b начинается
+// не раньше, чем автобус доедет холостым перегоном от конца a до начала b
+func (t *TimeTable) Follows(a, b path.Path) bool {
+	if b.StartTime.Before(a.EndTime) {
+		return false
+	}
+	dh, ok := t.Deadhead(a.Last().ID(), b.Points[0].ID())
+	return ok && !a.EndTime.Add(dh).After(b.StartTime)
+}
+
+// DeadheadMove - холостой перегон автобуса между двумя его путями
+type DeadheadMove = timetable.DeadheadMove
+
+// DeadheadMoves - холостые перегоны, которые следуют из текущего назначения
+// автобусов: автобус уезжает сразу после пути, если следующий путь начинается
+// в другой точке. Перегоны идут по автобусам, а у автобуса - по времени.
+// Невозможные перегоны (нет пути между точками) не включаются.
+func (t *TimeTable) DeadheadMoves() []DeadheadMove {
+	byBus := make(map[uuid.UUID][]path.Path)
+	var busIDs []uuid.UUID
+	for _, p := range t.GetEach(func(p path.Path) bool { return p.BusID != uuid.Nil }) {
+		if _, ok := byBus[p.BusID]; !ok {
+			busIDs = append(busIDs, p.BusID)
+		}
+		byBus[p.BusID] = append(byBus[p.BusID], p)
+	}
+	slices.SortFunc(busIDs, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	var moves []DeadheadMove
+	for _, id := range busIDs {
+		ps := byBus[id]
+		slices.SortFunc(ps, func(a, b path.Path) int {
+			if c := a.StartTime.Compare(b.StartTime); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID.String(), b.ID.String())
+		})
+		for i := 1; i < len(ps); i++ {
+			from, to := ps[i-1].Points[len(ps[i-1].Points)-1], ps[i].Points[0]
+			if from.ID() == to.ID() {
+				continue
+			}
+			dh, ok := t.Deadhead(from.ID(), to.ID())
+			if !ok {
+				continue
+			}
+			moves = append(moves, DeadheadMove{
+				BusID:      id,
+				From:       from,
+				To:         to,
+				Depart:     ps[i-1].EndTime,
+				Arrive:     ps[i-1].EndTime.Add(dh),
+				NextPathID: ps[i].ID,
+			})
+		}
+	}
+	return moves
+}
+
+// shortestFrom - алгоритм Дейкстры от точки src
+func (t *TimeTable) shortestFrom(src uuid.UUID) map[uuid.UUID]time.Duration {
+	dist := map[uuid.UUID]time.Duration{src: 0}
+	q := &dstQueue{{id: src}}
+	for q.Len() > 0 {
+		cur := heap.Pop(q).(dstItem)
+		if cur.dur > dist[cur.id] {
+			continue
+		}
+		for next, d := range t.stationsDistances[cur.id] {
+			nd := cur.dur + d
+			if old, ok := dist[next]; !ok || nd < old {
+				dist[next] = nd
+				heap.Push(q, dstItem{id: next, dur: nd})
+			}
+		}
+	}
+	return dist
+}
+
+type dstItem struct {
+	id  uuid.UUID
+	dur time.Duration
+}
+
+type dstQueue []dstItem
+
+func (q dstQueue) Len() int           { return len(q) }
+func (q dstQueue) Less(i, j int) bool { return q[i].dur < q[j].dur }
+func (q dstQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *dstQueue) Push(x any)        { *q = append(*q, x.(dstItem)) }
+func (q *dstQueue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
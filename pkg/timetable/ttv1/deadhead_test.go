@@ -0,0 +1,52 @@
+package ttv1
+
+import (
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// перегон идет по кратчайшему пути графа, а назначение автобусов
+// дает перегоны только там, где следующий путь начинается в другой точке
+func TestDeadheadMoves(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	pt := func(name byte) path.Point {
+		return path.Point{Id: uuid.UUID{name}, Name: string(name), IsBusStation: true}
+	}
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	trip := func(n int, from, to byte, start, end time.Time) path.Path {
+		return path.Path{ID: uuid.UUID{byte(n)}, Number: n, Points: []path.Point{pt(from), pt(to)},
+			StartTime: start, EndTime: end}
+	}
+
+	ttb := NewBuilder()
+	// B-C напрямую 40 минут, а через A по перегонам путей - 5 + 5
+	ttb.AddPath(trip(1, 'A', 'B', at(6, 0), at(7, 0)), []path.DstItem{
+		{From: pt('B').Id, To: pt('C').Id, Dur: 40 * time.Minute},
+	})
+	ttb.AddPath(trip(2, 'C', 'A', at(7, 30), at(8, 30)), nil)
+	ttb.AddPath(trip(3, 'A', 'B', at(9, 0), at(10, 0)), nil)
+	tt := ttb.Build()
+
+	if dh, ok := tt.Deadhead(pt('B').Id, pt('C').Id); !ok || dh != 10*time.Minute {
+		t.Errorf("Deadhead(B, C) = %s, %v, want 10m0s", dh, ok)
+	}
+	if _, ok := tt.Deadhead(pt('B').Id, uuid.UUID{'Z'}); ok {
+		t.Error("Deadhead to an unknown point is possible")
+	}
+
+	bus := uuid.UUID{0xb1}
+	for _, id := range []uuid.UUID{{1}, {2}, {3}} {
+		tt.AssignBusToPath(id, bus)
+	}
+	moves := tt.DeadheadMoves()
+	if len(moves) != 1 {
+		t.Fatalf("DeadheadMoves = %+v, want one move", moves)
+	}
+	m := moves[0]
+	if m.BusID != bus || m.From.ID() != pt('B').Id || m.To.ID() != pt('C').Id ||
+		!m.Depart.Equal(at(7, 0)) || !m.Arrive.Equal(at(7, 10)) || m.NextPathID != (uuid.UUID{2}) {
+		t.Errorf("move = %+v", m)
+	}
+}
@@ -2,22 +2,51 @@ package ttv1
 
 import (
 	"course/pkg/path"
+	"course/pkg/timetable"
 	"github.com/google/uuid"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
 
+// TimeTable - реализация timetable.Timetable на map путей с обходом
+// по отсортированным идентификаторам
 type TimeTable struct {
 	mu                sync.RWMutex
 	paths             map[uuid.UUID]path.Path
 	stationsDistances map[uuid.UUID]map[uuid.UUID]time.Duration
 	stations          map[uuid.UUID]path.Station
+	// идентификаторы путей в порядке возрастания: по ним идут все обходы,
+	// чтобы результат не зависел от порядка обхода map
+	order []uuid.UUID
+	// изменения назначений открытых транзакций
+	journal timetable.Journal
+
+	// кэш кратчайших перегонов по точке отправления
+	dhMu      sync.Mutex
+	deadheads map[uuid.UUID]map[uuid.UUID]time.Duration
 }
 
+var _ timetable.Timetable = (*TimeTable)(nil)
+
+// Paths - копия путей: вызывающий может менять ее, не трогая расписание
 func (t *TimeTable) Paths() map[uuid.UUID]path.Path {
-	return t.paths
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return maps.Clone(t.paths)
 }
+
+// PathIDs - копия идентификаторов всех путей в постоянном порядке
+func (t *TimeTable) PathIDs() []uuid.UUID {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Clone(t.order)
+}
+
 func (t *TimeTable) PathsLen() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return len(t.paths)
 }
 
@@ -25,8 +54,8 @@ func (t *TimeTable) GetFirstN(n int, fn func(p path.Path) bool) []uuid.UUID {
 	res := make([]uuid.UUID, 0, n)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	for k, v := range t.paths {
-		if fn(v) {
+	for _, k := range t.order {
+		if fn(t.paths[k]) {
 			res = append(res, k)
 			if len(res) == n {
 				break
@@ -61,13 +90,62 @@ func (t *TimeTable) GetPathToTime(timeTo time.Time) uuid.UUID {
 	return bestKey
 }
 
+func (t *TimeTable) NextPathAfter(after time.Time) (path.Path, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var (
+		next  path.Path
+		found bool
+	)
+	for _, k := range t.order {
+		p := t.paths[k]
+		if p.StartTime.After(after) && (!found || timetable.ComparePaths(p, next) < 0) {
+			next, found = p, true
+		}
+	}
+	return next, found
+}
+
+func (t *TimeTable) PathsByStart() []path.Path {
+	return t.sortedPaths(func(path.Path) bool { return true })
+}
+
+func (t *TimeTable) DriverPathBefore(driverID uuid.UUID, before time.Time) (path.Path, bool) {
+	return t.LastBefore(before, func(p path.Path) bool { return p.DriverID == driverID })
+}
+
+func (t *TimeTable) BusPathBefore(busID uuid.UUID, before time.Time) (path.Path, bool) {
+	return t.LastBefore(before, func(p path.Path) bool { return p.BusID == busID })
+}
+
+func (t *TimeTable) DriverPaths(driverID uuid.UUID) []path.Path {
+	return t.sortedPaths(func(p path.Path) bool { return p.DriverID == driverID })
+}
+
+func (t *TimeTable) BusPaths(busID uuid.UUID) []path.Path {
+	return t.sortedPaths(func(p path.Path) bool { return p.BusID == busID })
+}
+
+func (t *TimeTable) sortedPaths(fn func(p path.Path) bool) []path.Path {
+	var res []path.Path
+	t.mu.RLock()
+	for _, k := range t.order {
+		if p := t.paths[k]; fn(p) {
+			res = append(res, p)
+		}
+	}
+	t.mu.RUnlock()
+	slices.SortFunc(res, timetable.ComparePaths)
+	return res
+}
+
 func (t *TimeTable) getEachPath(fn func(path path.Path) bool) []uuid.UUID {
 	paths := make([]uuid.UUID, 0)
 
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	for k, v := range t.paths {
-		if fn(v) {
+	for _, k := range t.order {
+		if fn(t.paths[k]) {
 			paths = append(paths, k)
 		}
 	}
@@ -93,7 +171,7 @@ func (t *TimeTable) BusOnTheWayToTime(timeTo time.Time, busID uuid.UUID) bool {
 
 func (t *TimeTable) DriverOnTheWayToTime(timeTo time.Time, driverID uuid.UUID) bool {
 	paths := t.getEachPath(func(path path.Path) bool {
-		return path.BusID == driverID
+		return path.DriverID == driverID
 	})
 
 	t.mu.RLock()
@@ -112,6 +190,9 @@ func (t *TimeTable) AssignDriverToPath(pathID uuid.UUID, driverID uuid.UUID) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	p := t.paths[pathID]
+	if p.DriverID != driverID {
+		t.journal.Record(timetable.Change{PathID: pathID, From: p.DriverID, To: driverID})
+	}
 	p.DriverID = driverID
 	t.paths[pathID] = p
 }
@@ -120,10 +201,67 @@ func (t *TimeTable) AssignBusToPath(pathID uuid.UUID, busID uuid.UUID) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	p := t.paths[pathID]
+	if p.BusID != busID {
+		t.journal.Record(timetable.Change{PathID: pathID, Bus: true, From: p.BusID, To: busID})
+	}
 	p.BusID = busID
 	t.paths[pathID] = p
 }
 
+func (t *TimeTable) Begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.journal.Begin()
+}
+
+func (t *TimeTable) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.journal.Commit()
+}
+
+func (t *TimeTable) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changes, err := t.journal.Rollback()
+	for _, c := range changes {
+		t.revert(c)
+	}
+	return err
+}
+
+func (t *TimeTable) Undo() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.journal.Undo()
+	if ok {
+		t.revert(c)
+	}
+	return ok
+}
+
+// revert возвращает назначение до изменения c, не записывая его в журнал
+func (t *TimeTable) revert(c timetable.Change) {
+	p, ok := t.paths[c.PathID]
+	if !ok {
+		return
+	}
+	if c.Bus {
+		p.BusID = c.From
+	} else {
+		p.DriverID = c.From
+	}
+	t.paths[c.PathID] = p
+}
+
+// RemovePath убирает путь из расписания, например при отмене рейса
+func (t *TimeTable) RemovePath(pathID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.paths, pathID)
+	t.order = slices.DeleteFunc(slices.Clone(t.order), func(id uuid.UUID) bool { return id == pathID })
+}
+
 func (t *TimeTable) GetPathByID(pathID uuid.UUID) path.Path {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -135,10 +273,27 @@ func (t *TimeTable) GetEach(fn func(p path.Path) bool) map[uuid.UUID]path.Path {
 	res := make(map[uuid.UUID]path.Path)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	for _, p := range t.paths {
-		if fn(p) {
+	for _, k := range t.order {
+		if p := t.paths[k]; fn(p) {
 			res[p.ID] = p
 		}
 	}
 	return res
 }
+
+// LastBefore - путь из отобранных fn, закончившийся не позже at позже всех;
+// при равном конце - первый из них в порядке PathIDs. ok == false, если таких нет.
+func (t *TimeTable) LastBefore(at time.Time, fn func(p path.Path) bool) (p path.Path, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, k := range t.order {
+		o := t.paths[k]
+		if o.EndTime.After(at) || !fn(o) {
+			continue
+		}
+		if !ok || o.EndTime.After(p.EndTime) {
+			p, ok = o, true
+		}
+	}
+	return p, ok
+}
@@ -0,0 +1,52 @@
+package ttv1
+
+import (
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestLastBefore(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := NewBuilder()
+	for n, h := range map[int][2]int{1: {6, 12}, 2: {7, 8}, 3: {9, 10}, 4: {10, 12}} {
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(n)},
+			Number:    n,
+			Points:    []path.Point{a, b},
+			StartTime: day.Add(time.Duration(h[0]) * time.Hour),
+			EndTime:   day.Add(time.Duration(h[1]) * time.Hour),
+		}, nil)
+	}
+	tt := ttb.Build()
+
+	all := func(path.Path) bool { return true }
+	tests := []struct {
+		name string
+		fn   func(path.Path) bool
+		at   int
+		// номер пути, 0 - пути нет
+		want int
+	}{
+		{"none selected", func(path.Path) bool { return false }, 12, 0},
+		{"before all ends", all, 7, 0},
+		{"at an end", all, 8, 2},
+		{"latest end wins", all, 11, 3},
+		{"equal ends, first in order", all, 12, 1},
+		{"only selected", func(p path.Path) bool { return p.Number != 1 }, 12, 4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := 0
+			if p, ok := tt.LastBefore(day.Add(time.Duration(tc.at)*time.Hour), tc.fn); ok {
+				got = p.Number
+			}
+			if got != tc.want {
+				t.Errorf("LastBefore(%d:00) = path %d, want %d", tc.at, got, tc.want)
+			}
+		})
+	}
+}
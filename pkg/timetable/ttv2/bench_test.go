@@ -0,0 +1,207 @@
+package ttv2_test
+
+import (
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"course/pkg/timetable/timetabletest"
+	"course/pkg/timetable/ttv2"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Замеры запросов, которые оптимизаторы делают в горячих циклах, на ttv1 и ttv2.
+// Подзамер называется реализацией и размером расписания, например ttv1-10k.
+
+var sizes = []struct {
+	name string
+	n    int
+}{
+	{"10k", 10_000},
+	{"100k", 100_000},
+}
+
+// fixture - ttv1 и ttv2 с одними путями и назначениями и то, что спрашивают замеры:
+// водители и автобусы назначений, середины путей
+type fixture struct {
+	impls          []impl
+	ids            []uuid.UUID
+	drivers, buses []uuid.UUID
+	times          []time.Time
+}
+
+type impl struct {
+	name string
+	tt   timetable.Timetable
+}
+
+var fixtures = make(map[int]*fixture)
+
+// load строит расписания из n путей один раз на размер: синтетическое расписание
+// на 100k путей строится дольше, чем идет большинство замеров
+func load(n int) *fixture {
+	if f, ok := fixtures[n]; ok {
+		return f
+	}
+	v1 := timetabletest.Synthetic(1, n).Build()
+	// в среднем шесть путей на водителя и на автобус
+	f := &fixture{ids: v1.PathIDs()}
+	f.drivers, f.buses = assignRoundRobin(v1, max(n/6, 1), max(n/6, 1))
+	f.impls = []impl{{"ttv1", v1}, {"ttv2", ttv2.New(v1)}}
+	for _, id := range f.ids {
+		p := v1.GetPathByID(id)
+		f.times = append(f.times, p.StartTime.Add(p.EndTime.Sub(p.StartTime)/2))
+	}
+	fixtures[n] = f
+	return f
+}
+
+// assignRoundRobin назначает пути tt по времени начала по кругу drivers
+// водителям и buses автобусам: так у каждого водителя и автобуса пути
+// разбросаны по всему дню, как в расписании после оптимизатора
+func assignRoundRobin(tt timetable.Timetable, drivers, buses int) (ds, bs []uuid.UUID) {
+	rnd := rand.New(rand.NewPCG(1, 1))
+	newID := func() uuid.UUID {
+		var id uuid.UUID
+		for i := range id {
+			id[i] = byte(rnd.Uint32())
+		}
+		return id
+	}
+	ds = make([]uuid.UUID, drivers)
+	for i := range ds {
+		ds[i] = newID()
+	}
+	bs = make([]uuid.UUID, buses)
+	for i := range bs {
+		bs[i] = newID()
+	}
+	ps := make([]path.Path, 0, tt.PathsLen())
+	for _, p := range tt.Paths() {
+		ps = append(ps, p)
+	}
+	slices.SortFunc(ps, timetable.ComparePaths)
+	for i, p := range ps {
+		tt.AssignDriverToPath(p.ID, ds[i%drivers])
+		tt.AssignBusToPath(p.ID, bs[i%buses])
+	}
+	return ds, bs
+}
+
+// run запускает fn на каждой реализации каждого размера
+func run(b *testing.B, fn func(b *testing.B, tt timetable.Timetable, f *fixture)) {
+	for _, size := range sizes {
+		f := load(size.n)
+		for _, im := range f.impls {
+			b.Run(im.name+"-"+size.name, func(b *testing.B) { fn(b, im.tt, f) })
+		}
+	}
+}
+
+// pick - индексы в [0, n) из источника с постоянным зерном:
+// реализации получают одну и ту же последовательность запросов
+func pick(n int) func() int {
+	rnd := rand.New(rand.NewPCG(1, uint64(n)))
+	return func() int { return rnd.IntN(n) }
+}
+
+func BenchmarkDriverOnTheWayToTime(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		d, t := pick(len(f.drivers)), pick(len(f.times))
+		for i := 0; i < b.N; i++ {
+			tt.DriverOnTheWayToTime(f.times[t()], f.drivers[d()])
+		}
+	})
+}
+
+func BenchmarkBusOnTheWayToTime(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		bs, t := pick(len(f.buses)), pick(len(f.times))
+		for i := 0; i < b.N; i++ {
+			tt.BusOnTheWayToTime(f.times[t()], f.buses[bs()])
+		}
+	})
+}
+
+func BenchmarkGetPathToTime(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		t := pick(len(f.times))
+		for i := 0; i < b.N; i++ {
+			tt.GetPathToTime(f.times[t()])
+		}
+	})
+}
+
+func BenchmarkNextPathAfter(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		t := pick(len(f.times))
+		for i := 0; i < b.N; i++ {
+			tt.NextPathAfter(f.times[t()])
+		}
+	})
+}
+
+func BenchmarkDriverPaths(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		d := pick(len(f.drivers))
+		for i := 0; i < b.N; i++ {
+			tt.DriverPaths(f.drivers[d()])
+		}
+	})
+}
+
+// переназначение возвращает путь прежнему водителю, так что расписание не меняется
+func BenchmarkAssignDriverToPath(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		p, d := pick(len(f.ids)), pick(len(f.drivers))
+		for i := 0; i < b.N; i++ {
+			id := f.ids[p()]
+			old := tt.GetPathByID(id).DriverID
+			tt.AssignDriverToPath(id, f.drivers[d()])
+			tt.AssignDriverToPath(id, old)
+		}
+	})
+}
+
+func BenchmarkDriverPathBefore(b *testing.B) {
+	run(b, func(b *testing.B, tt timetable.Timetable, f *fixture) {
+		d, t := pick(len(f.drivers)), pick(len(f.times))
+		for i := 0; i < b.N; i++ {
+			tt.DriverPathBefore(f.drivers[d()], f.times[t()])
+		}
+	})
+}
+
+// худший случай для поиска последнего пути по началу: все пути у одного
+// водителя, и его первый путь идет весь день, накрывая остальные
+func BenchmarkDriverPathBeforeOneDriver(b *testing.B) {
+	for _, size := range sizes {
+		ttb := timetabletest.Synthetic(1, size.n)
+		long := uuid.UUID{0xff}
+		ttb.AddPath(path.Path{
+			ID:        long,
+			Number:    size.n + 1,
+			StartTime: time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, time.November, 30, 23, 59, 0, 0, time.UTC),
+		}, nil)
+		v1 := ttb.Build()
+		drv := uuid.UUID{0xd1}
+		var times []time.Time
+		for _, id := range v1.PathIDs() {
+			v1.AssignDriverToPath(id, drv)
+			if id != long {
+				times = append(times, v1.GetPathByID(id).EndTime)
+			}
+		}
+		for _, im := range []impl{{"ttv1", v1}, {"ttv2", ttv2.New(v1)}} {
+			b.Run(im.name+"-"+size.name, func(b *testing.B) {
+				t := pick(len(times))
+				for i := 0; i < b.N; i++ {
+					im.tt.DriverPathBefore(drv, times[t()])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ttv2
+
+import (
+	"bytes"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"slices"
+	"sort"
+	"time"
+)
+
+// index - пути одного водителя или автобуса в порядке timetable.ComparePaths
+// с префиксным максимумом концов. Как в дереве интервалов, вопрос
+// "идет ли путь в момент t" решается двоичным поиском: среди путей,
+// начавшихся раньше t, есть закончившийся позже t.
+// Те же пути в порядке концов дают последний путь до момента тоже
+// двоичным поиском.
+type index struct {
+	paths []path.Path
+	// maxEnd[i] - самый поздний конец среди paths[:i+1]
+	maxEnd []time.Time
+	// byEnd - те же пути в порядке compareEnds
+	byEnd []path.Path
+}
+
+// compareEnds - порядок путей по концу, при равном конце - по идентификатору
+func compareEnds(a, b path.Path) int {
+	if c := a.EndTime.Compare(b.EndTime); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func (ix *index) insert(p path.Path) {
+	i, _ := slices.BinarySearchFunc(ix.paths, p, timetable.ComparePaths)
+	ix.paths = slices.Insert(ix.paths, i, p)
+	ix.maxEnd = slices.Insert(ix.maxEnd, i, time.Time{})
+	ix.fix(i)
+	j, _ := slices.BinarySearchFunc(ix.byEnd, p, compareEnds)
+	ix.byEnd = slices.Insert(ix.byEnd, j, p)
+}
+
+func (ix *index) remove(p path.Path) {
+	i, ok := slices.BinarySearchFunc(ix.paths, p, timetable.ComparePaths)
+	if !ok {
+		return
+	}
+	ix.paths = slices.Delete(ix.paths, i, i+1)
+	ix.maxEnd = slices.Delete(ix.maxEnd, i, i+1)
+	ix.fix(i)
+	if j, ok := slices.BinarySearchFunc(ix.byEnd, p, compareEnds); ok {
+		ix.byEnd = slices.Delete(ix.byEnd, j, j+1)
+	}
+}
+
+// replace подменяет путь с тем же началом, концом и идентификатором,
+// например после смены водителя или автобуса
+func (ix *index) replace(p path.Path) {
+	if i, ok := slices.BinarySearchFunc(ix.paths, p, timetable.ComparePaths); ok {
+		ix.paths[i] = p
+	}
+	if j, ok := slices.BinarySearchFunc(ix.byEnd, p, compareEnds); ok {
+		ix.byEnd[j] = p
+	}
+}
+
+// build достраивает индекс, в paths которого пути уже лежат по порядку
+func (ix *index) build() {
+	ix.maxEnd = make([]time.Time, len(ix.paths))
+	ix.fix(0)
+	ix.byEnd = slices.Clone(ix.paths)
+	slices.SortFunc(ix.byEnd, compareEnds)
+}
+
+// fix пересчитывает maxEnd начиная с позиции i
+func (ix *index) fix(i int) {
+	for ; i < len(ix.paths); i++ {
+		end := ix.paths[i].EndTime
+		if i > 0 && ix.maxEnd[i-1].After(end) {
+			end = ix.maxEnd[i-1]
+		}
+		ix.maxEnd[i] = end
+	}
+}
+
+// busyAt - идет ли какой-нибудь путь строго внутри момента t
+func (ix *index) busyAt(t time.Time) bool {
+	n := sort.Search(len(ix.paths), func(i int) bool { return !ix.paths[i].StartTime.Before(t) })
+	return n > 0 && ix.maxEnd[n-1].After(t)
+}
+
+// lastBefore - timetable.Timetable.LastBefore по путям индекса: последний
+// из путей, закончившихся не позже t, и первый из путей с тем же концом
+func (ix *index) lastBefore(t time.Time) (path.Path, bool) {
+	n := sort.Search(len(ix.byEnd), func(i int) bool { return ix.byEnd[i].EndTime.After(t) })
+	if n == 0 {
+		return path.Path{}, false
+	}
+	end := ix.byEnd[n-1].EndTime
+	i := sort.Search(n, func(i int) bool { return !ix.byEnd[i].EndTime.Before(end) })
+	return ix.byEnd[i], true
+}
@@ -0,0 +1,135 @@
+package ttv2
+
+import (
+	"course/pkg/path"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+func hm(h, m int) time.Time {
+	return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+// newIndex - индекс из путей [from, to), вставленных в данном порядке
+func newIndex(spans ...[2]time.Time) *index {
+	ix := &index{}
+	for i, s := range spans {
+		ix.insert(path.Path{ID: uuid.UUID{byte(i + 1)}, Number: i + 1, StartTime: s[0], EndTime: s[1]})
+	}
+	return ix
+}
+
+func TestIndexBusyAt(t *testing.T) {
+	// длинный путь с 6 до 12 накрывает короткие, а после него есть пробел
+	ix := newIndex(
+		[2]time.Time{hm(8, 0), hm(9, 0)},
+		[2]time.Time{hm(6, 0), hm(12, 0)},
+		[2]time.Time{hm(10, 0), hm(10, 30)},
+		[2]time.Time{hm(14, 0), hm(15, 0)},
+	)
+	tests := []struct {
+		name string
+		ix   *index
+		at   time.Time
+		want bool
+	}{
+		{"empty", &index{}, hm(8, 0), false},
+		{"before all", ix, hm(5, 0), false},
+		{"at the first start", ix, hm(6, 0), false},
+		{"inside the long path", ix, hm(7, 0), true},
+		{"after a short path, inside the long one", ix, hm(11, 0), true},
+		{"at the end of the long path", ix, hm(12, 0), false},
+		{"in the gap", ix, hm(13, 0), false},
+		{"inside the last path", ix, hm(14, 30), true},
+		{"at the last end", ix, hm(15, 0), false},
+		{"after all", ix, hm(16, 0), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.ix.busyAt(tc.at); got != tc.want {
+				t.Errorf("busyAt(%s) = %v, want %v", tc.at.Format("15:04"), got, tc.want)
+			}
+		})
+	}
+}
+
+// после удаления длинного пути maxEnd пересчитывается и пробелы становятся видны
+func TestIndexRemove(t *testing.T) {
+	ix := newIndex(
+		[2]time.Time{hm(6, 0), hm(12, 0)},
+		[2]time.Time{hm(8, 0), hm(9, 0)},
+	)
+	ix.remove(ix.paths[0])
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{hm(7, 0), false},
+		{hm(8, 30), true},
+		{hm(10, 0), false},
+	}
+	for _, tc := range tests {
+		if got := ix.busyAt(tc.at); got != tc.want {
+			t.Errorf("busyAt(%s) after remove = %v, want %v", tc.at.Format("15:04"), got, tc.want)
+		}
+	}
+	if p, ok := ix.lastBefore(hm(12, 0)); !ok || p.Number != 2 {
+		t.Errorf("lastBefore(12:00) after remove = path %d, %v, want 2", p.Number, ok)
+	}
+}
+
+func TestIndexLastBefore(t *testing.T) {
+	ix := newIndex(
+		[2]time.Time{hm(6, 0), hm(12, 0)},
+		[2]time.Time{hm(7, 0), hm(8, 0)},
+		[2]time.Time{hm(9, 0), hm(10, 0)},
+		[2]time.Time{hm(13, 0), hm(14, 0)},
+		[2]time.Time{hm(13, 30), hm(14, 0)},
+	)
+	tests := []struct {
+		name string
+		at   time.Time
+		// номер пути, 0 - пути нет
+		want int
+	}{
+		{"before all", hm(6, 0), 0},
+		{"at the first end", hm(8, 0), 2},
+		{"under the long path", hm(11, 0), 3},
+		{"at the end of the long path", hm(12, 0), 1},
+		{"while the last path runs", hm(13, 30), 1},
+		{"equal ends, smaller id", hm(15, 0), 4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := 0
+			if p, ok := ix.lastBefore(tc.at); ok {
+				got = p.Number
+			}
+			if got != tc.want {
+				t.Errorf("lastBefore(%s) = path %d, want %d", tc.at.Format("15:04"), got, tc.want)
+			}
+		})
+	}
+}
+
+// индекс, собранный build из упорядоченных путей, совпадает с собранным вставками
+func TestIndexBuild(t *testing.T) {
+	ix := newIndex(
+		[2]time.Time{hm(9, 0), hm(10, 0)},
+		[2]time.Time{hm(6, 0), hm(12, 0)},
+		[2]time.Time{hm(7, 0), hm(8, 0)},
+		[2]time.Time{hm(7, 0), hm(10, 0)},
+	)
+	built := &index{paths: slices.Clone(ix.paths)}
+	built.build()
+	if !slices.Equal(built.maxEnd, ix.maxEnd) {
+		t.Errorf("maxEnd = %v, want %v", built.maxEnd, ix.maxEnd)
+	}
+	if !slices.EqualFunc(built.byEnd, ix.byEnd, func(a, b path.Path) bool { return a.ID == b.ID }) {
+		t.Errorf("byEnd differs from the one built by inserts")
+	}
+}
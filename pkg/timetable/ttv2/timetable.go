@@ -0,0 +1,438 @@
+package ttv2
+
+import (
+	"bytes"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeTable - реализация timetable.Timetable с индексами: пути водителя
+// и автобуса хранятся по началу (см. index), все пути - по началу.
+// Занятость водителя и автобуса, пути водителя и автобуса, их последний путь
+// до момента и ближайший путь после момента находятся двоичным поиском,
+// а не обходом всех путей.
+// Запросы с произвольным условием (GetEach, GetFirstN, LastBefore)
+// по-прежнему обходят все пути.
+type TimeTable struct {
+	mu    sync.RWMutex
+	paths map[uuid.UUID]path.Path
+	// идентификаторы путей в порядке возрастания, как в ttv1
+	order []uuid.UUID
+	// все пути в порядке timetable.ComparePaths
+	byStart  []startKey
+	byDriver map[uuid.UUID]*index
+	byBus    map[uuid.UUID]*index
+	// изменения назначений открытых транзакций
+	journal timetable.Journal
+
+	// граф перегонов между остановками; пути base не используются
+	base *ttv1.TimeTable
+}
+
+type startKey struct {
+	start time.Time
+	id    uuid.UUID
+}
+
+func compareKeys(a, b startKey) int {
+	if c := a.start.Compare(b.start); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.id[:], b.id[:])
+}
+
+var _ timetable.Timetable = (*TimeTable)(nil)
+
+// New строит индексированное расписание с путями и назначениями base.
+// Перегоны считаются по графу остановок base, дальше base не меняется
+// и может не использоваться.
+func New(base *ttv1.TimeTable) *TimeTable {
+	t := &TimeTable{
+		paths:    make(map[uuid.UUID]path.Path, base.PathsLen()),
+		order:    slices.Clone(base.PathIDs()),
+		byDriver: make(map[uuid.UUID]*index),
+		byBus:    make(map[uuid.UUID]*index),
+		base:     base,
+	}
+	t.byStart = make([]startKey, 0, len(t.order))
+	for _, id := range t.order {
+		p := base.GetPathByID(id)
+		t.paths[id] = p
+		t.byStart = append(t.byStart, startKey{p.StartTime, id})
+	}
+	slices.SortFunc(t.byStart, compareKeys)
+
+	// индексы собираются из отсортированных путей сразу, без вставок по одному
+	for _, k := range t.byStart {
+		p := t.paths[k.id]
+		if p.DriverID != uuid.Nil {
+			appendSorted(t.byDriver, p.DriverID, p)
+		}
+		if p.BusID != uuid.Nil {
+			appendSorted(t.byBus, p.BusID, p)
+		}
+	}
+	for _, ix := range t.byDriver {
+		ix.build()
+	}
+	for _, ix := range t.byBus {
+		ix.build()
+	}
+	return t
+}
+
+func appendSorted(idx map[uuid.UUID]*index, key uuid.UUID, p path.Path) {
+	ix, ok := idx[key]
+	if !ok {
+		ix = &index{}
+		idx[key] = ix
+	}
+	ix.paths = append(ix.paths, p)
+}
+
+func (t *TimeTable) Paths() map[uuid.UUID]path.Path {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return maps.Clone(t.paths)
+}
+
+// PathIDs - копия порядка путей: вызывающий может менять ее, не трогая индексы
+func (t *TimeTable) PathIDs() []uuid.UUID {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Clone(t.order)
+}
+
+func (t *TimeTable) PathsLen() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.paths)
+}
+
+func (t *TimeTable) GetPathByID(pathID uuid.UUID) path.Path {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.paths[pathID]
+}
+
+func (t *TimeTable) GetEach(fn func(p path.Path) bool) map[uuid.UUID]path.Path {
+	res := make(map[uuid.UUID]path.Path)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, k := range t.order {
+		if p := t.paths[k]; fn(p) {
+			res[k] = p
+		}
+	}
+	return res
+}
+
+func (t *TimeTable) LastBefore(at time.Time, fn func(p path.Path) bool) (p path.Path, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, k := range t.order {
+		o := t.paths[k]
+		if o.EndTime.After(at) || !fn(o) {
+			continue
+		}
+		if !ok || o.EndTime.After(p.EndTime) {
+			p, ok = o, true
+		}
+	}
+	return p, ok
+}
+
+func (t *TimeTable) GetFirstN(n int, fn func(p path.Path) bool) []uuid.UUID {
+	res := make([]uuid.UUID, 0, n)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, k := range t.order {
+		if fn(t.paths[k]) {
+			res = append(res, k)
+			if len(res) == n {
+				break
+			}
+		}
+	}
+	return res
+}
+
+// GetPathToTime выбирает, как ttv1, самый поздний свободный путь окна,
+// а при равном начале - с меньшим идентификатором. Окно находится
+// двоичным поиском и просматривается с конца до первого свободного пути.
+func (t *TimeTable) GetPathToTime(timeTo time.Time) uuid.UUID {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	lo := sort.Search(len(t.byStart), func(i int) bool { return t.byStart[i].start.After(timeTo) })
+	hi := sort.Search(len(t.byStart), func(i int) bool { return !t.byStart[i].start.Before(timeTo.Add(30 * time.Minute)) })
+
+	best := uuid.Nil
+	var bestStart time.Time
+	for i := hi - 1; i >= lo; i-- {
+		k := t.byStart[i]
+		if best != uuid.Nil && k.start.Before(bestStart) {
+			break
+		}
+		if p := t.paths[k.id]; p.BusID == uuid.Nil && p.DriverID == uuid.Nil {
+			best, bestStart = k.id, k.start
+		}
+	}
+	return best
+}
+
+func (t *TimeTable) NextPathAfter(after time.Time) (path.Path, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i := sort.Search(len(t.byStart), func(i int) bool { return t.byStart[i].start.After(after) })
+	if i == len(t.byStart) {
+		return path.Path{}, false
+	}
+	return t.paths[t.byStart[i].id], true
+}
+
+func (t *TimeTable) PathsByStart() []path.Path {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	res := make([]path.Path, len(t.byStart))
+	for i, k := range t.byStart {
+		res[i] = t.paths[k.id]
+	}
+	return res
+}
+
+func (t *TimeTable) DriverPathBefore(driverID uuid.UUID, before time.Time) (path.Path, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if ix, ok := t.byDriver[driverID]; ok {
+		return ix.lastBefore(before)
+	}
+	return path.Path{}, false
+}
+
+func (t *TimeTable) BusPathBefore(busID uuid.UUID, before time.Time) (path.Path, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if ix, ok := t.byBus[busID]; ok {
+		return ix.lastBefore(before)
+	}
+	return path.Path{}, false
+}
+
+func (t *TimeTable) DriverPaths(driverID uuid.UUID) []path.Path {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if ix, ok := t.byDriver[driverID]; ok {
+		return slices.Clone(ix.paths)
+	}
+	return nil
+}
+
+func (t *TimeTable) BusPaths(busID uuid.UUID) []path.Path {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if ix, ok := t.byBus[busID]; ok {
+		return slices.Clone(ix.paths)
+	}
+	return nil
+}
+
+func (t *TimeTable) BusOnTheWayToTime(timeTo time.Time, busID uuid.UUID) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ix, ok := t.byBus[busID]
+	return ok && ix.busyAt(timeTo)
+}
+
+func (t *TimeTable) DriverOnTheWayToTime(timeTo time.Time, driverID uuid.UUID) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ix, ok := t.byDriver[driverID]
+	return ok && ix.busyAt(timeTo)
+}
+
+func (t *TimeTable) AssignDriverToPath(pathID uuid.UUID, driverID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if from, ok := t.setDriver(pathID, driverID); ok {
+		t.journal.Record(timetable.Change{PathID: pathID, From: from, To: driverID})
+	}
+}
+
+func (t *TimeTable) AssignBusToPath(pathID uuid.UUID, busID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if from, ok := t.setBus(pathID, busID); ok {
+		t.journal.Record(timetable.Change{PathID: pathID, Bus: true, From: from, To: busID})
+	}
+}
+
+// setDriver меняет водителя пути вместе с индексами и возвращает прежнего;
+// ok == false, если пути нет или водитель тот же
+func (t *TimeTable) setDriver(pathID uuid.UUID, driverID uuid.UUID) (from uuid.UUID, ok bool) {
+	p, ok := t.paths[pathID]
+	if !ok || p.DriverID == driverID {
+		return uuid.Nil, false
+	}
+	from = p.DriverID
+	unindex(t.byDriver, p.DriverID, p)
+	p.DriverID = driverID
+	t.paths[pathID] = p
+	reindex(t.byDriver, driverID, p)
+	// пути в индексе автобуса хранятся целиком, их водитель тоже меняется
+	if ix, ok := t.byBus[p.BusID]; ok {
+		ix.replace(p)
+	}
+	return from, true
+}
+
+// setBus - то же, что setDriver, для автобуса
+func (t *TimeTable) setBus(pathID uuid.UUID, busID uuid.UUID) (from uuid.UUID, ok bool) {
+	p, ok := t.paths[pathID]
+	if !ok || p.BusID == busID {
+		return uuid.Nil, false
+	}
+	from = p.BusID
+	unindex(t.byBus, p.BusID, p)
+	p.BusID = busID
+	t.paths[pathID] = p
+	reindex(t.byBus, busID, p)
+	if ix, ok := t.byDriver[p.DriverID]; ok {
+		ix.replace(p)
+	}
+	return from, true
+}
+
+func (t *TimeTable) Begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.journal.Begin()
+}
+
+func (t *TimeTable) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.journal.Commit()
+}
+
+func (t *TimeTable) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changes, err := t.journal.Rollback()
+	for _, c := range changes {
+		t.revert(c)
+	}
+	return err
+}
+
+func (t *TimeTable) Undo() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.journal.Undo()
+	if ok {
+		t.revert(c)
+	}
+	return ok
+}
+
+// revert возвращает назначение до изменения c, не записывая его в журнал
+func (t *TimeTable) revert(c timetable.Change) {
+	if c.Bus {
+		t.setBus(c.PathID, c.From)
+	} else {
+		t.setDriver(c.PathID, c.From)
+	}
+}
+
+func (t *TimeTable) RemovePath(pathID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.paths[pathID]
+	if !ok {
+		return
+	}
+	unindex(t.byDriver, p.DriverID, p)
+	unindex(t.byBus, p.BusID, p)
+	delete(t.paths, pathID)
+	t.order = slices.DeleteFunc(t.order, func(id uuid.UUID) bool { return id == pathID })
+	i, found := slices.BinarySearchFunc(t.byStart, startKey{p.StartTime, pathID}, compareKeys)
+	if found {
+		t.byStart = slices.Delete(t.byStart, i, i+1)
+	}
+}
+
+func unindex(idx map[uuid.UUID]*index, key uuid.UUID, p path.Path) {
+	if key == uuid.Nil {
+		return
+	}
+	if ix, ok := idx[key]; ok {
+		ix.remove(p)
+		if len(ix.paths) == 0 {
+			delete(idx, key)
+		}
+	}
+}
+
+func reindex(idx map[uuid.UUID]*index, key uuid.UUID, p path.Path) {
+	if key == uuid.Nil {
+		return
+	}
+	ix, ok := idx[key]
+	if !ok {
+		ix = &index{}
+		idx[key] = ix
+	}
+	ix.insert(p)
+}
+
+func (t *TimeTable) Deadhead(from, to uuid.UUID) (time.Duration, bool) {
+	return t.base.Deadhead(from, to)
+}
+
+func (t *TimeTable) Follows(a, b path.Path) bool {
+	return t.base.Follows(a, b)
+}
+
+// DeadheadMoves - перегоны по индексу автобусов, в том же порядке, что у ttv1
+func (t *TimeTable) DeadheadMoves() []timetable.DeadheadMove {
+	t.mu.RLock()
+	busIDs := make([]uuid.UUID, 0, len(t.byBus))
+	byBus := make(map[uuid.UUID][]path.Path, len(t.byBus))
+	for id, ix := range t.byBus {
+		busIDs = append(busIDs, id)
+		byBus[id] = slices.Clone(ix.paths)
+	}
+	t.mu.RUnlock()
+	slices.SortFunc(busIDs, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	var moves []timetable.DeadheadMove
+	for _, id := range busIDs {
+		ps := byBus[id]
+		for i := 1; i < len(ps); i++ {
+			from, to := ps[i-1].Points[len(ps[i-1].Points)-1], ps[i].Points[0]
+			if from.ID() == to.ID() {
+				continue
+			}
+			dh, ok := t.Deadhead(from.ID(), to.ID())
+			if !ok {
+				continue
+			}
+			moves = append(moves, timetable.DeadheadMove{
+				BusID:      id,
+				From:       from,
+				To:         to,
+				Depart:     ps[i-1].EndTime,
+				Arrive:     ps[i-1].EndTime.Add(dh),
+				NextPathID: ps[i].ID,
+			})
+		}
+	}
+	return moves
+}
@@ -0,0 +1,13 @@
+package ttv2_test
+
+import (
+	"course/pkg/timetable"
+	"course/pkg/timetable/timetabletest"
+	"course/pkg/timetable/ttv2"
+	"testing"
+)
+
+func TestTimetable(t *testing.T) {
+	ttb := timetabletest.Synthetic(1, 200)
+	timetabletest.Run(t, func() timetable.Timetable { return ttv2.New(ttb.Build()) })
+}
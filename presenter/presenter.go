@@ -2,13 +2,14 @@ package presenter
 
 import (
 	"course/pkg/driverhub"
-	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable"
 	"fmt"
 	"github.com/google/uuid"
 	"os"
+	"slices"
 	"strings"
+	"time"
 )
 
 type Presenter struct {
@@ -16,13 +17,15 @@ type Presenter struct {
 
 func (p *Presenter) Present(
 	filename string,
-	tt *ttv1.TimeTable,
+	seed uint64,
+	tt timetable.Timetable,
 	dh *driverhub.DriverHub,
 	bst *station.BusStation,
 ) {
 	var builder strings.Builder
 
 	builder.WriteString("# Расписание и сводная информация\n\n")
+	builder.WriteString(fmt.Sprintf("## Зерно эксперимента: %d\n\n", seed))
 	builder.WriteString(fmt.Sprintf("## Количество автобусов: %d\n\n", len(bst.Buses())))
 	builder.WriteString(fmt.Sprintf("## Количество водителей: %d\n\n", len(dh.Drivers())))
 	// Таблица расписания
@@ -48,10 +51,9 @@ func (p *Presenter) Present(
 	builder.WriteString("| Водитель | Рабочие промежутки | Количество путей |\n")
 	builder.WriteString("|----------|--------------------|------------------|\n")
 	for id, d := range dh.Drivers() {
-		paths := tt.GetEach(func(p path.Path) bool { return p.DriverID == id })
+		paths := tt.DriverPaths(id)
 		var intervals []string
-		for _, pid := range paths {
-			p := tt.GetPathByID(pid.ID)
+		for _, p := range paths {
 			intervals = append(intervals, fmt.Sprintf("%s-%s", p.StartTime.Format("15:04"), p.EndTime.Format("15:04")))
 		}
 		builder.WriteString(fmt.Sprintf(
@@ -69,8 +71,85 @@ func (p *Presenter) Present(
 	builder.WriteString("| Автобус | Количество путей |\n")
 	builder.WriteString("|---------|------------------|\n")
 	for id, b := range bst.Buses() {
-		paths := tt.GetEach(func(p path.Path) bool { return p.BusID == id })
-		builder.WriteString(fmt.Sprintf("| %s | %d |\n", b.ID.String(), len(paths)))
+		builder.WriteString(fmt.Sprintf("| %s | %d |\n", b.ID.String(), len(tt.BusPaths(id))))
+	}
+
+	builder.WriteString("\n")
+
+	// Рабочий день каждого автобуса от выезда из депо до возврата
+	builder.WriteString("## Блоки автобусов\n\n")
+	builder.WriteString("| Автобус | Депо | Выезд | Пути | Возврат | Машинное время |\n")
+	builder.WriteString("|---------|------|-------|------|---------|----------------|\n")
+	for _, blk := range bst.Blocks(tt) {
+		if len(blk.Paths) == 0 {
+			continue
+		}
+		var trips []string
+		for _, p := range blk.Paths {
+			trips = append(trips, fmt.Sprintf("%d %s-%s", p.Number, p.StartTime.Format("15:04"), p.EndTime.Format("15:04")))
+		}
+		builder.WriteString(fmt.Sprintf(
+			"| %s | %s | %s | %s | %s | %s |\n",
+			getBusName(bst, blk.BusID),
+			blk.Depot.Name,
+			blk.Start().Format("15:04"),
+			strings.Join(trips, ", "),
+			blk.End().Format("15:04"),
+			blk.Duration(),
+		))
+	}
+
+	builder.WriteString("\n")
+
+	// Холостые перегоны автобусов между путями
+	moves := tt.DeadheadMoves()
+	var idle time.Duration
+	for _, m := range moves {
+		idle += m.Arrive.Sub(m.Depart)
+	}
+	builder.WriteString("## Холостые перегоны\n\n")
+	builder.WriteString(fmt.Sprintf("Количество: %d, суммарное время: %s\n\n", len(moves), idle))
+	builder.WriteString("| Автобус | Откуда | Куда | Отправление | Прибытие |\n")
+	builder.WriteString("|---------|--------|------|-------------|----------|\n")
+	for _, m := range moves {
+		builder.WriteString(fmt.Sprintf(
+			"| %s | %s | %s | %s | %s |\n",
+			getBusName(bst, m.BusID),
+			m.From.Name,
+			m.To.Name,
+			m.Depart.Format("15:04"),
+			m.Arrive.Format("15:04"),
+		))
+	}
+
+	builder.WriteString("\n")
+
+	// Соответствие смен трудовым правилам типа водителя
+	builder.WriteString("## Соответствие трудовым правилам\n\n")
+	builder.WriteString("| Водитель | Тип | Правило | Статус | Причина |\n")
+	builder.WriteString("|----------|-----|---------|--------|---------|\n")
+	drivers := dh.Drivers()
+	ids := make([]uuid.UUID, 0, len(drivers))
+	for id := range drivers {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	for _, id := range ids {
+		d := drivers[id]
+		for _, v := range d.Spec().Report(tt.DriverPaths(id)) {
+			status := "соблюдено"
+			if !v.OK {
+				status = "нарушено"
+			}
+			builder.WriteString(fmt.Sprintf(
+				"| %s | %s | %s | %s | %s |\n",
+				id.String(),
+				d.Spec().Name,
+				v.Rule,
+				status,
+				v.Reason,
+			))
+		}
 	}
 
 	// Сохранение в файл
@@ -0,0 +1,55 @@
+package presenter
+
+import (
+	"course/roster"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var weekdays = [...]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+
+// PresentRoster сохраняет календарь графика: строка на водителя,
+// столбец на день, в клетке - время смены или выходной
+func (p *Presenter) PresentRoster(filename string, r *roster.Roster) {
+	var builder strings.Builder
+
+	builder.WriteString("# График водителей\n\n")
+	builder.WriteString(fmt.Sprintf("## Период: %s - %s\n\n",
+		r.Date(0).Format("02.01.2006"), r.Date(r.Days-1).Format("02.01.2006")))
+	builder.WriteString(fmt.Sprintf("## Количество водителей в штате: %d\n\n", len(r.Employees)))
+
+	builder.WriteString("## Календарь\n\n")
+	builder.WriteString("| Водитель | Тип |")
+	sep := "|----------|-----|"
+	for day := 0; day < r.Days; day++ {
+		date := r.Date(day)
+		builder.WriteString(fmt.Sprintf(" %s %s |", date.Format("02.01"), weekdays[date.Weekday()]))
+		sep += "-------|"
+	}
+	builder.WriteString(" Дней | Часов |\n")
+	builder.WriteString(sep + "------|-------|\n")
+	for _, e := range r.Employees {
+		builder.WriteString(fmt.Sprintf("| %s | %s |", e.Name, e.Spec.Name))
+		for _, d := range e.Days {
+			if d == nil {
+				builder.WriteString(" вых |")
+				continue
+			}
+			builder.WriteString(fmt.Sprintf(" %s-%s |", d.Start().Format("15:04"), d.End().Format("15:04")))
+		}
+		builder.WriteString(fmt.Sprintf(" %d | %.1f |\n", e.WorkDays(), e.Hours().Hours()))
+	}
+
+	file, err := os.Create(fmt.Sprintf("%s.md", filename))
+	if err != nil {
+		fmt.Println("Ошибка создания файла:", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(builder.String()); err != nil {
+		fmt.Println("Ошибка записи в файл:", err)
+		return
+	}
+}
@@ -0,0 +1,354 @@
+package repair
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kind - вид сбоя
+type Kind int
+
+const (
+	// DriverUnavailable - водитель не может работать начиная с From
+	DriverUnavailable Kind = iota
+	// BusOutOfService - автобус сломался и не выходит на линию начиная с From
+	BusOutOfService
+	// TripCancelled - рейс PathID отменен
+	TripCancelled
+)
+
+func (k Kind) String() string {
+	switch k {
+	case DriverUnavailable:
+		return "driver unavailable"
+	case BusOutOfService:
+		return "bus out of service"
+	case TripCancelled:
+		return "trip cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Disruption - сбой в уже назначенном расписании.
+// Заполняется идентификатор, соответствующий Kind.
+type Disruption struct {
+	Kind     Kind
+	DriverID uuid.UUID
+	BusID    uuid.UUID
+	PathID   uuid.UUID
+	From     time.Time
+}
+
+// Change - одно изменение назначения пути. Незатронутая часть назначения
+// совпадает в From и To.
+type Change struct {
+	PathID     uuid.UUID
+	Number     int
+	Start      time.Time
+	FromDriver uuid.UUID
+	ToDriver   uuid.UUID
+	FromBus    uuid.UUID
+	ToBus      uuid.UUID
+	// путь убран из расписания
+	Cancelled bool
+	// под путь нанят новый водитель или взят новый автобус
+	Hired bool
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Cancelled:
+		return fmt.Sprintf("path %d at %s: cancelled", c.Number, c.Start.Format("15:04"))
+	case c.FromDriver != c.ToDriver:
+		return fmt.Sprintf("path %d at %s: driver %s -> %s%s",
+			c.Number, c.Start.Format("15:04"), c.FromDriver, c.ToDriver, hired(c.Hired))
+	default:
+		return fmt.Sprintf("path %d at %s: bus %s -> %s%s",
+			c.Number, c.Start.Format("15:04"), c.FromBus, c.ToBus, hired(c.Hired))
+	}
+}
+
+func hired(ok bool) string {
+	if ok {
+		return " (new)"
+	}
+	return ""
+}
+
+var (
+	ErrUnknownDriver = errors.New("driver is not registered")
+	ErrUnknownBus    = errors.New("bus is not registered")
+	ErrUnknownPath   = errors.New("path is not in the timetable")
+	ErrStarted       = errors.New("path has already started")
+)
+
+// Repair чинит назначенное расписание после сбоя d в момент now
+// (обычно clock.C().Now()). Меняются только пути, которые затронул сбой
+// и которые еще не начались; все остальные назначения остаются как были.
+// Путь, уже идущий в момент сбоя, водитель и автобус доводят до конца.
+// Блоки, изменившиеся после сбоя автобуса или отмены пути, заново
+// приписываются к депо (station.BusStation.AllocateDepots).
+// Возвращает список сделанных изменений.
+func Repair(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	drvs *driverhub.DriverHub,
+	d Disruption,
+	now time.Time,
+) ([]Change, error) {
+	from := d.From
+	if from.Before(now) {
+		from = now
+	}
+
+	switch d.Kind {
+	case DriverUnavailable:
+		if drvs.GetDriver(d.DriverID) == nil {
+			return nil, ErrUnknownDriver
+		}
+		affected := future(tt, from, func(p path.Path) bool { return p.DriverID == d.DriverID })
+		return reassignDrivers(tt, drvs, d.DriverID, affected), nil
+
+	case BusOutOfService:
+		if buses.GetBus(d.BusID) == nil {
+			return nil, ErrUnknownBus
+		}
+		affected := future(tt, from, func(p path.Path) bool { return p.BusID == d.BusID })
+		changes := reassignBuses(tt, buses, d.BusID, affected)
+		// новый автобус и удлинившийся блок запасного приписываются
+		// к ближайшему депо, как после оптимизаторов
+		buses.AllocateDepots(tt)
+		return changes, nil
+
+	case TripCancelled:
+		p := tt.GetPathByID(d.PathID)
+		if p.ID == uuid.Nil {
+			return nil, ErrUnknownPath
+		}
+		if p.StartTime.Before(now) {
+			return nil, ErrStarted
+		}
+		tt.RemovePath(p.ID)
+		buses.AllocateDepots(tt)
+		return []Change{{
+			PathID:     p.ID,
+			Number:     p.Number,
+			Start:      p.StartTime,
+			FromDriver: p.DriverID,
+			FromBus:    p.BusID,
+			Cancelled:  true,
+		}}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown disruption kind %d", d.Kind)
+	}
+}
+
+// future - пути, отобранные fn и начинающиеся не раньше from, по времени начала
+func future(tt timetable.Timetable, from time.Time, fn func(p path.Path) bool) []path.Path {
+	var ps []path.Path
+	for _, p := range tt.GetEach(func(p path.Path) bool { return fn(p) && !p.StartTime.Before(from) }) {
+		ps = append(ps, p)
+	}
+	slices.SortFunc(ps, byStart)
+	return ps
+}
+
+// reassignDrivers отдает каждый путь водителю штата, который может взять его
+// по правилам своего типа, а из таких - освободившемуся позже всех, чтобы
+// не растягивать чужие смены. Если такого нет, нанимается водитель
+// того же типа, что и выбывший; следующие пути сначала пробуют его.
+func reassignDrivers(
+	tt timetable.Timetable,
+	drvs *driverhub.DriverHub,
+	out uuid.UUID,
+	affected []path.Path,
+) []Change {
+	typ := drvs.GetDriver(out).Type()
+
+	var changes []Change
+	for _, p := range affected {
+		var (
+			best    driver.Driver
+			bestEnd time.Time
+		)
+		for _, id := range sortedDrivers(drvs) {
+			if id == out {
+				continue
+			}
+			d := drvs.GetDriver(id)
+			own := assigned(tt, func(o path.Path) bool { return o.DriverID == id })
+			if !canTake(d, own, p) {
+				continue
+			}
+			end := lastEndBefore(own, p.StartTime)
+			if best == nil || end.After(bestEnd) {
+				best, bestEnd = d, end
+			}
+		}
+
+		isNew := best == nil
+		if isNew {
+			best = driver.New(drvs.NewID(), typ)
+			drvs.Register(best)
+		}
+		tt.AssignDriverToPath(p.ID, best.ID())
+		changes = append(changes, Change{
+			PathID:     p.ID,
+			Number:     p.Number,
+			Start:      p.StartTime,
+			FromDriver: p.DriverID,
+			ToDriver:   best.ID(),
+			FromBus:    p.BusID,
+			ToBus:      p.BusID,
+			Hired:      isNew,
+		})
+	}
+	return changes
+}
+
+// reassignBuses переносит оставшуюся цепочку сломанного автобуса целиком
+// на один автобус парка, который свободен до ее конца и успевает
+// к ее началу холостым перегоном. Цепочка уже согласована по точкам, поэтому
+// других путей трогать не нужно. Если такого автобуса нет, берется новый,
+// даже если цепочку можно было бы разделить между несколькими автобусами
+// парка: ремонт меняет как можно меньше назначений, а не экономит автобусы.
+func reassignBuses(
+	tt timetable.Timetable,
+	buses *station.BusStation,
+	out uuid.UUID,
+	affected []path.Path,
+) []Change {
+	if len(affected) == 0 {
+		return nil
+	}
+	first, last := affected[0], affected[len(affected)-1]
+
+	var spare *bus.Bus
+	for _, id := range sortedBuses(buses) {
+		if id == out {
+			continue
+		}
+		own := assigned(tt, func(o path.Path) bool { return o.BusID == id })
+		if canTakeChain(tt, own, first, last) {
+			spare = buses.GetBus(id)
+			break
+		}
+	}
+
+	isNew := spare == nil
+	if isNew {
+		spare = bus.NewBus(buses.NewID())
+		buses.Register(spare)
+	}
+
+	changes := make([]Change, 0, len(affected))
+	for _, p := range affected {
+		tt.AssignBusToPath(p.ID, spare.ID)
+		changes = append(changes, Change{
+			PathID:     p.ID,
+			Number:     p.Number,
+			Start:      p.StartTime,
+			FromDriver: p.DriverID,
+			ToDriver:   p.DriverID,
+			FromBus:    p.BusID,
+			ToBus:      spare.ID,
+			Hired:      isNew,
+		})
+	}
+	return changes
+}
+
+// canTake - может ли водитель d с путями own взять еще и p
+func canTake(d driver.Driver, own []path.Path, p path.Path) bool {
+	for _, o := range own {
+		if overlaps(o, p) {
+			return false
+		}
+	}
+	return driver.NewShift(d, append(slices.Clone(own), p)).Legal()
+}
+
+// canTakeChain - свободен ли автобус с путями own от начала first до конца last,
+// успевает ли он к началу first и после last к своему следующему пути
+// с учетом холостых перегонов
+func canTakeChain(tt timetable.Timetable, own []path.Path, first, last path.Path) bool {
+	var (
+		prev, next       path.Path
+		hasPrev, hasNext bool
+	)
+	for _, o := range own {
+		if o.StartTime.Before(last.EndTime) && first.StartTime.Before(o.EndTime) {
+			return false
+		}
+		if !o.EndTime.After(first.StartTime) {
+			prev, hasPrev = o, true
+		}
+		if !hasNext && !o.StartTime.Before(last.EndTime) {
+			next, hasNext = o, true
+		}
+	}
+	if hasPrev && !tt.Follows(prev, first) {
+		return false
+	}
+	return !hasNext || tt.Follows(last, next)
+}
+
+func assigned(tt timetable.Timetable, fn func(p path.Path) bool) []path.Path {
+	var ps []path.Path
+	for _, p := range tt.GetEach(fn) {
+		ps = append(ps, p)
+	}
+	slices.SortFunc(ps, byStart)
+	return ps
+}
+
+func lastEndBefore(ps []path.Path, t time.Time) time.Time {
+	var end time.Time
+	for _, p := range ps {
+		if !p.EndTime.After(t) && p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	return end
+}
+
+func overlaps(a, b path.Path) bool {
+	return a.StartTime.Before(b.EndTime) && b.StartTime.Before(a.EndTime)
+}
+
+func byStart(a, b path.Path) int {
+	if c := a.StartTime.Compare(b.StartTime); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID.String(), b.ID.String())
+}
+
+func sortedDrivers(drvs *driverhub.DriverHub) []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for id := range drvs.Drivers() {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
+
+func sortedBuses(buses *station.BusStation) []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for id := range buses.Buses() {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
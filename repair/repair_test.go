@@ -0,0 +1,256 @@
+package repair
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// конечные A и B
+var ends = map[byte]path.Point{
+	'A': {Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true},
+	'B': {Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true},
+}
+
+// trip - путь из from в to с часа start до часа end, который ведет
+// водитель drv на автобусе bus; номера с единицы
+type trip struct {
+	from, to   byte
+	start, end int
+	drv, bus   int
+}
+
+// change - ожидаемое изменение пути номер n. drv и bus - номер водителя
+// и автобуса сцены после ремонта, 0 - новый, нанятый при ремонте.
+type change struct {
+	n         int
+	drv, bus  int
+	hired     bool
+	cancelled bool
+}
+
+// scene - два водителя типа A и два автобуса с путями trips
+type scene struct {
+	tt    *ttv1.TimeTable
+	drvs  *driverhub.DriverHub
+	buses *station.BusStation
+	ps    []path.Path
+	dIDs  []uuid.UUID
+	bIDs  []uuid.UUID
+}
+
+func newScene(trips []trip) *scene {
+	sc := &scene{
+		dIDs: []uuid.UUID{{0xd1}, {0xd2}},
+		bIDs: []uuid.UUID{{0xb1}, {0xb2}},
+	}
+	dhb := driverhub.NewDriverHubBuilder()
+	for _, id := range sc.dIDs {
+		dhb.AddDriver(driver.New(id, "A"))
+	}
+	bsb := station.NewBusStationBuilder()
+	for _, id := range sc.bIDs {
+		bsb.AddBus(bus.NewBus(id))
+	}
+	ttb := ttv1.NewBuilder()
+	for i, tr := range trips {
+		p := path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{ends[tr.from], ends[tr.to]},
+			StartTime: day.Add(time.Duration(tr.start) * time.Hour),
+			EndTime:   day.Add(time.Duration(tr.end) * time.Hour),
+		}
+		sc.ps = append(sc.ps, p)
+		ttb.AddPath(p, nil)
+	}
+	sc.tt, sc.drvs, sc.buses = ttb.Build(), dhb.Build(), bsb.Build()
+	for i, tr := range trips {
+		sc.tt.AssignDriverToPath(sc.ps[i].ID, sc.dIDs[tr.drv-1])
+		sc.tt.AssignBusToPath(sc.ps[i].ID, sc.bIDs[tr.bus-1])
+	}
+	return sc
+}
+
+// number - номер водителя или автобуса id в ids с единицы, 0 - его нет в сцене
+func number(ids []uuid.UUID, id uuid.UUID) int {
+	for i, o := range ids {
+		if o == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func TestRepair(t *testing.T) {
+	// первый водитель и автобус ведут три пути подряд, второй - один
+	shift := func(spare trip) []trip {
+		return []trip{{'A', 'B', 6, 8, 1, 1}, {'B', 'A', 9, 11, 1, 1}, {'A', 'B', 12, 13, 1, 1}, spare}
+	}
+
+	tests := []struct {
+		name  string
+		trips []trip
+		kind  Kind
+		// номер водителя, автобуса или пути, которых касается сбой;
+		// -1 - тех, кого нет в сцене
+		target int
+		// час сбоя, он же текущий момент
+		at   int
+		want []change
+		err  error
+	}{
+		{
+			// путь, идущий в момент сбоя, первый водитель доводит до конца
+			name: "driver replaced by a spare", trips: shift(trip{'B', 'A', 8, 9, 2, 2}),
+			kind: DriverUnavailable, target: 1, at: 7,
+			want: []change{{n: 2, drv: 2, bus: 1}, {n: 3, drv: 2, bus: 1}},
+		},
+		{
+			// второй водитель занят во время второго пути; третий путь
+			// достается нанятому, освободившемуся позже
+			name: "driver hired when no spare fits", trips: shift(trip{'A', 'B', 9, 10, 2, 2}),
+			kind: DriverUnavailable, target: 1, at: 7,
+			want: []change{{n: 2, drv: 0, bus: 1, hired: true}, {n: 3, drv: 0, bus: 1}},
+		},
+		{
+			// второй автобус после своего пути стоит в B, где начинается цепочка
+			name: "bus chain moved to one spare", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: BusOutOfService, target: 1, at: 8,
+			want: []change{{n: 2, drv: 1, bus: 2}, {n: 3, drv: 1, bus: 2}},
+		},
+		{
+			// второй автобус стоит в A и успевает перегоном в B к началу цепочки
+			name: "bus spare reaches the chain by deadhead", trips: shift(trip{'B', 'A', 6, 7, 2, 2}),
+			kind: BusOutOfService, target: 1, at: 8,
+			want: []change{{n: 2, drv: 1, bus: 2}, {n: 3, drv: 1, bus: 2}},
+		},
+		{
+			// второй автобус занят во время второго пути, хотя третий мог бы взять:
+			// цепочка не делится, и под нее берется новый автобус
+			name: "bus taken new when no spare fits", trips: shift(trip{'B', 'A', 9, 10, 2, 2}),
+			kind: BusOutOfService, target: 1, at: 8,
+			want: []change{{n: 2, drv: 1, bus: 0, hired: true}, {n: 3, drv: 1, bus: 0, hired: true}},
+		},
+		{
+			name: "trip cancelled", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: TripCancelled, target: 3, at: 8,
+			want: []change{{n: 3, drv: 1, bus: 1, cancelled: true}},
+		},
+		{
+			name: "started trip not cancelled", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: TripCancelled, target: 1, at: 7, err: ErrStarted,
+		},
+		{
+			name: "unknown driver", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: DriverUnavailable, target: -1, at: 7, err: ErrUnknownDriver,
+		},
+		{
+			name: "unknown bus", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: BusOutOfService, target: -1, at: 7, err: ErrUnknownBus,
+		},
+		{
+			name: "unknown path", trips: shift(trip{'A', 'B', 6, 7, 2, 2}),
+			kind: TripCancelled, target: -1, at: 7, err: ErrUnknownPath,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sc := newScene(tc.trips)
+			now := day.Add(time.Duration(tc.at) * time.Hour)
+			d := Disruption{Kind: tc.kind, From: now}
+			pick := func(ids []uuid.UUID) uuid.UUID {
+				if tc.target < 0 {
+					return uuid.UUID{0xee}
+				}
+				return ids[tc.target-1]
+			}
+			switch tc.kind {
+			case DriverUnavailable:
+				d.DriverID = pick(sc.dIDs)
+			case BusOutOfService:
+				d.BusID = pick(sc.bIDs)
+			case TripCancelled:
+				pIDs := make([]uuid.UUID, len(sc.ps))
+				for i, p := range sc.ps {
+					pIDs[i] = p.ID
+				}
+				d.PathID = pick(pIDs)
+			}
+
+			before := make(map[uuid.UUID]path.Path)
+			for id, p := range sc.tt.GetEach(func(path.Path) bool { return true }) {
+				before[id] = p
+			}
+			changes, err := Repair(sc.tt, sc.buses, sc.drvs, d, now)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+
+			var got []change
+			changed := make(map[uuid.UUID]bool)
+			for _, c := range changes {
+				changed[c.PathID] = true
+				got = append(got, change{
+					n:         c.Number,
+					drv:       number(sc.dIDs, c.ToDriver),
+					bus:       number(sc.bIDs, c.ToBus),
+					hired:     c.Hired,
+					cancelled: c.Cancelled,
+				})
+				if c.Cancelled {
+					if sc.tt.GetPathByID(c.PathID).ID != uuid.Nil {
+						t.Errorf("path %d is still in the timetable", c.Number)
+					}
+					// у отмененного пути назначения не меняются
+					got[len(got)-1].drv = number(sc.dIDs, c.FromDriver)
+					got[len(got)-1].bus = number(sc.bIDs, c.FromBus)
+					continue
+				}
+				p := sc.tt.GetPathByID(c.PathID)
+				if p.DriverID != c.ToDriver || p.BusID != c.ToBus {
+					t.Errorf("path %d is assigned to %s/%s, change says %s/%s",
+						c.Number, p.DriverID, p.BusID, c.ToDriver, c.ToBus)
+				}
+				if c.ToDriver != uuid.Nil && sc.drvs.GetDriver(c.ToDriver) == nil {
+					t.Errorf("path %d: driver %s is not registered", c.Number, c.ToDriver)
+				}
+				if sc.buses.GetBus(c.ToBus) == nil {
+					t.Errorf("path %d: bus %s is not registered", c.Number, c.ToBus)
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("changes = %+v, want %+v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("change %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+			// нанятый при ремонте водитель ведет все переданные ему пути
+			if len(changes) > 1 && tc.want[0].drv == 0 && changes[0].ToDriver != changes[1].ToDriver {
+				t.Errorf("hired drivers %s and %s, want one", changes[0].ToDriver, changes[1].ToDriver)
+			}
+
+			// все, что сбой не затронул, осталось как было
+			for id, p := range sc.tt.GetEach(func(path.Path) bool { return true }) {
+				if changed[id] {
+					continue
+				}
+				if b := before[id]; p.DriverID != b.DriverID || p.BusID != b.BusID {
+					t.Errorf("untouched path %d changed: %s/%s -> %s/%s",
+						p.Number, b.DriverID, b.BusID, p.DriverID, p.BusID)
+				}
+			}
+		})
+	}
+}
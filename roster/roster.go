@@ -0,0 +1,279 @@
+package roster
+
+import (
+	"course/config"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/timetable"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Config - параметры графика
+type Config struct {
+	// сколько дней подряд планируется
+	Days int
+	// первый день графика; нулевое время - день сцены (scene.Date)
+	Start time.Time
+}
+
+func DefaultConfig() Config {
+	return Config{Days: 7}
+}
+
+// FromConfig берет параметры из конфигурации, незаданные остаются по умолчанию
+func FromConfig(c config.RosterConfig) (Config, error) {
+	cfg := DefaultConfig()
+	if c.Days > 0 {
+		cfg.Days = c.Days
+	}
+	if c.StartDate != "" {
+		start, err := time.Parse(time.DateOnly, c.StartDate)
+		if err != nil {
+			return Config{}, fmt.Errorf("roster start date: %w", err)
+		}
+		cfg.Start = start
+	}
+	return cfg, nil
+}
+
+// Duty - дневная смена: пути одного водителя из решения оптимизатора за день
+type Duty struct {
+	// полночь дня смены
+	Date time.Time
+	Type driver.DriverType
+	// водитель оптимизатора, который выполнял смену в дневном расписании
+	DriverID uuid.UUID
+	// пути в порядке начала
+	Paths []path.Path
+}
+
+func (d Duty) Start() time.Time { return d.Paths[0].StartTime }
+
+func (d Duty) End() time.Time {
+	end := d.Paths[0].EndTime
+	for _, p := range d.Paths[1:] {
+		if p.EndTime.After(end) {
+			end = p.EndTime
+		}
+	}
+	return end
+}
+
+// Hours - рабочее время смены от начала первого пути до конца последнего
+func (d Duty) Hours() time.Duration { return d.End().Sub(d.Start()) }
+
+// Duties - смены дневного расписания tt, по одной на каждого водителя с путями.
+// Смена переносится на день date целиком: сдвиг считается по дню первого пути,
+// так что пути после полуночи остаются в той же смене.
+// Смены идут по времени начала.
+func Duties(date time.Time, tt timetable.Timetable, drvs *driverhub.DriverHub) []Duty {
+	y, m, d := date.Date()
+	date = time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+
+	byDriver := make(map[uuid.UUID][]path.Path)
+	for _, p := range tt.GetEach(func(p path.Path) bool { return p.DriverID != uuid.Nil }) {
+		byDriver[p.DriverID] = append(byDriver[p.DriverID], p)
+	}
+
+	duties := make([]Duty, 0, len(byDriver))
+	for id, ps := range byDriver {
+		drv := drvs.GetDriver(id)
+		if drv == nil {
+			continue
+		}
+		slices.SortFunc(ps, func(a, b path.Path) int {
+			if c := a.StartTime.Compare(b.StartTime); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID.String(), b.ID.String())
+		})
+		py, pm, pd := ps[0].StartTime.Date()
+		shift := date.Sub(time.Date(py, pm, pd, 0, 0, 0, 0, ps[0].StartTime.Location()))
+		for i := range ps {
+			ps[i].StartTime = ps[i].StartTime.Add(shift)
+			ps[i].EndTime = ps[i].EndTime.Add(shift)
+		}
+		duties = append(duties, Duty{Date: date, Type: drv.Spec().Name, DriverID: id, Paths: ps})
+	}
+	slices.SortFunc(duties, compareDuties)
+	return duties
+}
+
+func compareDuties(a, b Duty) int {
+	if c := a.Start().Compare(b.Start()); c != 0 {
+		return c
+	}
+	return strings.Compare(a.DriverID.String(), b.DriverID.String())
+}
+
+// Employee - водитель в штате графика
+type Employee struct {
+	// имя вида "A-03": тип и номер среди водителей типа
+	Name string
+	Spec driver.Spec
+	// смены по дням графика; nil - выходной
+	Days []*Duty
+}
+
+// Hours - рабочее время за весь график
+func (e *Employee) Hours() time.Duration {
+	var h time.Duration
+	for _, d := range e.Days {
+		if d != nil {
+			h += d.Hours()
+		}
+	}
+	return h
+}
+
+// WorkDays - сколько дней графика водитель работает
+func (e *Employee) WorkDays() int {
+	n := 0
+	for _, d := range e.Days {
+		if d != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Roster - график работы водителей на несколько дней
+type Roster struct {
+	// первый день графика, полночь
+	Start     time.Time
+	Days      int
+	Employees []*Employee
+}
+
+// Date - полночь дня day графика
+func (r *Roster) Date(day int) time.Time { return r.Start.AddDate(0, 0, day) }
+
+// Plan раскладывает дневные смены days (days[i] - смены i-го дня графика)
+// по водителям штата. Смена достается водителю ее типа, который:
+//   - работает не больше WorkDays дней в любом окне из WorkDays+WeekendDays
+//     дней подряд (режим 5/2 для пяти рабочих и двух выходных);
+//   - отдохнул после предыдущей смены не меньше MinDailyRest;
+//   - не превысит WeeklyCap рабочего времени за календарную неделю;
+//   - не нарушит правил своего типа вместе со сменами с начала прошлой
+//     недели до конца следующей: так действуют недельные и двухнедельные
+//     ограничения вождения.
+//
+// Из подходящих выбирается отработавший меньше всех, при равенстве - по имени.
+// Если подходящего нет, в штат нанимается новый водитель.
+func Plan(start time.Time, days [][]Duty) *Roster {
+	y, m, d := start.Date()
+	r := &Roster{Start: time.Date(y, m, d, 0, 0, 0, 0, start.Location()), Days: len(days)}
+	count := make(map[driver.DriverType]int)
+
+	for day, duties := range days {
+		duties = slices.Clone(duties)
+		slices.SortFunc(duties, compareDuties)
+		for i := range duties {
+			duty := &duties[i]
+			var best *Employee
+			for _, e := range r.Employees {
+				if e.Spec.Name != duty.Type || !e.canWork(day, duty) {
+					continue
+				}
+				if best == nil || e.Hours() < best.Hours() ||
+					e.Hours() == best.Hours() && e.Name < best.Name {
+					best = e
+				}
+			}
+			if best == nil {
+				spec, _ := driver.Lookup(duty.Type)
+				count[duty.Type]++
+				best = &Employee{
+					Name: fmt.Sprintf("%s-%02d", duty.Type, count[duty.Type]),
+					Spec: spec,
+					Days: make([]*Duty, len(days)),
+				}
+				r.Employees = append(r.Employees, best)
+			}
+			best.Days[day] = duty
+		}
+	}
+	return r
+}
+
+// canWork - может ли водитель взять смену duty в день day графика
+func (e *Employee) canWork(day int, duty *Duty) bool {
+	if e.Days[day] != nil {
+		return false
+	}
+
+	// в окне из WorkDays+WeekendDays дней, заканчивающемся днем day,
+	// уже не больше WorkDays-1 рабочих дней
+	window := e.Spec.WorkDays + e.Spec.WeekendDays
+	worked := 0
+	for i := max(0, day-window+1); i < day; i++ {
+		if e.Days[i] != nil {
+			worked++
+		}
+	}
+	if worked >= e.Spec.WorkDays {
+		return false
+	}
+
+	for i := day - 1; i >= 0; i-- {
+		if prev := e.Days[i]; prev != nil {
+			if duty.Start().Sub(prev.End()) < e.Spec.MinDailyRest {
+				return false
+			}
+			break
+		}
+	}
+
+	week := weekStart(duty.Date)
+	hours := duty.Hours()
+	for _, d := range e.Days {
+		if d != nil && weekStart(d.Date).Equal(week) {
+			hours += d.Hours()
+		}
+	}
+	if hours > e.Spec.WeeklyCap {
+		return false
+	}
+
+	from, to := week.AddDate(0, 0, -7), week.AddDate(0, 0, 14)
+	var around []path.Path
+	for i, d := range e.Days {
+		if d != nil && i != day && !d.Date.Before(from) && d.Date.Before(to) {
+			around = append(around, d.Paths...)
+		}
+	}
+	return len(e.Spec.Conflicts(around, duty.Paths)) == 0
+}
+
+// Violations - нарушения графика: смены, которые не выдержали правил Plan.
+// Для графика из Plan список пуст; нужен, чтобы проверить график, поправленный вручную.
+func (r *Roster) Violations() []string {
+	var res []string
+	for _, e := range r.Employees {
+		for day, duty := range e.Days {
+			if duty == nil {
+				continue
+			}
+			e.Days[day] = nil
+			ok := e.canWork(day, duty)
+			e.Days[day] = duty
+			if !ok {
+				res = append(res, fmt.Sprintf("%s on %s: duty %s-%s breaks the work pattern, daily rest, weekly cap or labour rules",
+					e.Name, duty.Date.Format("02.01"), duty.Start().Format("15:04"), duty.End().Format("15:04")))
+			}
+		}
+	}
+	return res
+}
+
+// weekStart - полночь понедельника недели, в которую попадает t
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,134 @@
+package roster
+
+import (
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+	"time"
+)
+
+// monday - первый день графика в тестах
+var monday = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// duty - смена в день day графика с часа from до часа to
+func duty(day, from, to int) *Duty {
+	date := monday.AddDate(0, 0, day)
+	p := path.Path{
+		ID:        uuid.UUID{byte(day), byte(from), byte(to)},
+		StartTime: date.Add(time.Duration(from) * time.Hour),
+		EndTime:   date.Add(time.Duration(to) * time.Hour),
+	}
+	return &Duty{Date: date, Type: "A", Paths: []path.Path{p}}
+}
+
+func TestCanWork(t *testing.T) {
+	spec := driver.Spec{Name: "A", WorkDays: 5, WeekendDays: 2, MinDailyRest: 11 * time.Hour, WeeklyCap: 40 * time.Hour}
+	tests := []struct {
+		name   string
+		worked []*Duty
+		day    int
+		duty   *Duty
+		ok     bool
+	}{
+		{"first duty", nil, 0, duty(0, 8, 16), true},
+		{"day already taken", []*Duty{duty(0, 8, 16)}, 0, duty(0, 17, 20), false},
+		{"sixth day in a row", []*Duty{duty(0, 8, 14), duty(1, 8, 14), duty(2, 8, 14), duty(3, 8, 14), duty(4, 8, 14)},
+			5, duty(5, 8, 14), false},
+		{"after two days off", []*Duty{duty(0, 8, 14), duty(1, 8, 14), duty(2, 8, 14), duty(3, 8, 14), duty(4, 8, 14)},
+			7, duty(7, 8, 14), true},
+		{"short daily rest", []*Duty{duty(0, 14, 22)}, 1, duty(1, 6, 14), false},
+		{"daily rest after a day off", []*Duty{duty(0, 14, 22)}, 2, duty(2, 6, 14), true},
+		{"weekly cap reached", []*Duty{duty(0, 8, 16), duty(1, 8, 16), duty(2, 8, 16), duty(3, 8, 16)},
+			4, duty(4, 8, 16), true},
+		{"weekly cap exceeded", []*Duty{duty(0, 8, 16), duty(1, 8, 16), duty(2, 8, 16), duty(3, 8, 16)},
+			4, duty(4, 6, 16), false},
+		{"cap of the previous week", []*Duty{duty(2, 6, 16), duty(3, 6, 16), duty(4, 6, 16), duty(5, 6, 16)},
+			7, duty(7, 6, 16), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Employee{Name: "A-01", Spec: spec, Days: make([]*Duty, 14)}
+			for _, d := range tc.worked {
+				e.Days[int(d.Date.Sub(monday)/(24*time.Hour))] = d
+			}
+			if got := e.canWork(tc.day, tc.duty); got != tc.ok {
+				t.Errorf("canWork = %v, want %v", got, tc.ok)
+			}
+		})
+	}
+}
+
+// недельные и двухнедельные правила EU561 учитывают смены прошлой недели
+func TestCanWorkEU(t *testing.T) {
+	spec := driver.Spec{Name: "A", WorkDays: 5, WeekendDays: 2, MinDailyRest: 11 * time.Hour,
+		WeeklyCap: 56 * time.Hour, Rules: driver.EU561()}
+	// пять смен по 10 часов: 50 часов вождения в первую неделю
+	heavy := []*Duty{duty(0, 6, 16), duty(1, 6, 16), duty(2, 6, 16), duty(3, 6, 16), duty(4, 6, 16)}
+	tests := []struct {
+		name string
+		next []*Duty
+		day  int
+		ok   bool
+	}{
+		// 50 + 36 часов
+		{"fourth day of the next week", []*Duty{duty(7, 6, 15), duty(8, 6, 15), duty(9, 6, 15)}, 10, true},
+		// 50 + 45 - больше 90 за две недели
+		{"fifth day of the next week", []*Duty{duty(7, 6, 15), duty(8, 6, 15), duty(9, 6, 15), duty(10, 6, 15)}, 11, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Employee{Name: "A-01", Spec: spec, Days: make([]*Duty, 14)}
+			for _, d := range append(slices.Clone(heavy), tc.next...) {
+				e.Days[int(d.Date.Sub(monday)/(24*time.Hour))] = d
+			}
+			if got := e.canWork(tc.day, duty(tc.day, 6, 15)); got != tc.ok {
+				t.Errorf("canWork = %v, want %v", got, tc.ok)
+			}
+		})
+	}
+
+	r := &Roster{Start: monday, Days: 14, Employees: []*Employee{{Name: "A-01", Spec: spec, Days: make([]*Duty, 14)}}}
+	for _, d := range append(slices.Clone(heavy), duty(7, 6, 15), duty(8, 6, 15), duty(9, 6, 15), duty(10, 6, 15),
+		duty(11, 6, 15)) {
+		r.Employees[0].Days[int(d.Date.Sub(monday)/(24*time.Hour))] = d
+	}
+	if v := r.Violations(); len(v) == 0 {
+		t.Error("Violations is empty for 95 hours of driving in two weeks")
+	}
+}
+
+// смена через полночь переносится целиком и не заканчивается раньше начала
+func TestDutiesAcrossMidnight(t *testing.T) {
+	ttb := ttv1.NewBuilder()
+	late := path.Path{ID: uuid.UUID{1}, StartTime: monday.Add(22 * time.Hour), EndTime: monday.Add(23*time.Hour + 30*time.Minute)}
+	night := path.Path{ID: uuid.UUID{2}, StartTime: monday.Add(24*time.Hour + 30*time.Minute), EndTime: monday.Add(25*time.Hour + 30*time.Minute)}
+	ttb.AddPath(late, nil)
+	ttb.AddPath(night, nil)
+	tt := ttb.Build()
+
+	drv := driver.New(uuid.UUID{0xd1}, driver.Types()[0].Name)
+	dhb := driverhub.NewDriverHubBuilder()
+	dhb.AddDriver(drv)
+	tt.AssignDriverToPath(late.ID, drv.ID())
+	tt.AssignDriverToPath(night.ID, drv.ID())
+
+	date := monday.AddDate(0, 0, 7)
+	duties := Duties(date, tt, dhb.Build())
+	if len(duties) != 1 {
+		t.Fatalf("Duties = %d duties, want 1", len(duties))
+	}
+	d := duties[0]
+	if want := date.Add(22 * time.Hour); !d.Start().Equal(want) {
+		t.Errorf("Start = %s, want %s", d.Start(), want)
+	}
+	if want := date.Add(25*time.Hour + 30*time.Minute); !d.End().Equal(want) {
+		t.Errorf("End = %s, want %s", d.End(), want)
+	}
+	if d.Hours() != 3*time.Hour+30*time.Minute {
+		t.Errorf("Hours = %s, want 3h30m", d.Hours())
+	}
+}
@@ -3,52 +3,95 @@ package scene
 import (
 	"course/config"
 	"course/pkg/bus"
-	"course/pkg/clock"
+	"course/pkg/depot"
 	"course/pkg/driver"
 	"course/pkg/driverhub"
 	"course/pkg/path"
+	"course/pkg/random"
 	"course/pkg/station"
+	"course/pkg/timetable"
 	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable/ttv2"
 	"fmt"
-	"github.com/google/uuid"
-	"math/rand/v2"
+	"log"
 	"time"
 )
 
-func GenScene() (*ttv1.TimetableBuilder, *driverhub.DriverHubBuilder, *station.BusStationBuilder) {
+// defaultDate - день расписания, если в конфигурации он не задан
+var defaultDate = time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC)
+
+// Date - день, на который строится расписание сцены. Он задается
+// конфигурацией, а не часами, поэтому сцена по зерну повторяется в любой день.
+func Date() time.Time {
+	if config.C().SceneDate == "" {
+		return defaultDate
+	}
+	d, err := time.Parse(time.DateOnly, config.C().SceneDate)
+	if err != nil {
+		log.Fatalf("scene_date: %v", err)
+	}
+	return d
+}
+
+// GenScene генерирует сцену эксперимента; все случайные решения, включая
+// идентификаторы водителей и автобусов, которых наймут оптимизаторы,
+// берутся из rnd, поэтому одно зерно дает одну и ту же сцену. На каждой
+// конечной стоит депо со своими InitialBusCount автобусами, водители
+// делятся между депо поровну.
+func GenScene(rnd *random.Source) (*ttv1.TimetableBuilder, *depot.Builder) {
 	bss := make([]path.Point, 0, config.C().InitialBusStationsCount)
 	for i := 0; i < config.C().InitialBusStationsCount; i++ {
 		bss = append(bss, path.Point{
-			Id:           uuid.New(),
+			Id:           rnd.UUID(),
 			Name:         fmt.Sprintf("BusStation%d", i+1),
 			IsBusStation: true,
 		})
 	}
 
-	workStartTime := time.Date(0, 0, 0, 6, 0, 0, 0, time.Local)
-	workEndTime := time.Date(0, 0, 0, 23, 0, 0, 0, time.Local)
+	day := Date()
+	workStartTime := day.Add(6 * time.Hour)
+	workEndTime := day.Add(23 * time.Hour)
 
-	tt := genTimeTable(bss, config.C().DistinctPathCount, workStartTime, workEndTime)
-	stb := station.NewBusStationBuilder()
-	for range bss {
-		for i := 0; i < config.C().InitialBusCount; i++ {
-			stb.AddBus(bus.NewBus(uuid.New()))
+	tt := genTimeTable(rnd, bss, config.C().DistinctPathCount, workStartTime, workEndTime)
+	stbs := make([]*station.BusStationBuilder, len(bss))
+	hubs := make([]*driverhub.DriverHubBuilder, len(bss))
+	for i := range bss {
+		stbs[i] = station.NewBusStationBuilder()
+		for j := 0; j < config.C().InitialBusCount; j++ {
+			stbs[i].AddBus(bus.NewBus(rnd.UUID()))
 		}
+		hubs[i] = driverhub.NewDriverHubBuilder()
 	}
 
-	hub := driverhub.NewDriverHubBuilder()
-
-	for i := 0; i < config.C().InitialDriverACount; i++ {
-		hub.AddDriver(driver.NewDriverA())
+	for _, tc := range config.C().DriverTypes {
+		for i := 0; i < tc.InitialCount; i++ {
+			hubs[i%len(hubs)].AddDriver(driver.New(rnd.UUID(), tc.Name))
+		}
 	}
-	for i := 0; i < config.C().InitialDriverBCount; i++ {
-		hub.AddDriver(driver.NewDriverB())
+
+	depots := depot.NewBuilder()
+	for i, bs := range bss {
+		depots.Add(bs, stbs[i], hubs[i])
 	}
+	depots.SetSeed(rnd.Uint64())
+	return tt, depots
+}
 
-	return tt, hub, stb
+// Timetable строит расписание сцены реализацией из конфигурации
+func Timetable(ttb *ttv1.TimetableBuilder) timetable.Timetable {
+	switch config.C().Timetable {
+	case "", "ttv1":
+		return ttb.Build()
+	case "ttv2":
+		return ttv2.New(ttb.Build())
+	default:
+		log.Fatalf("unknown timetable %q", config.C().Timetable)
+		return nil
+	}
 }
 
 func genTimeTable(
+	rnd *random.Source,
 	busStations []path.Point,
 	pathsCount int,
 	workStart time.Time,
@@ -56,14 +99,14 @@ func genTimeTable(
 ) *ttv1.TimetableBuilder {
 	ttb := ttv1.NewBuilder()
 	inc := increment()
-	rndTime := randTime(workEnd, workStart)
+	rndTime := randTime(rnd, workEnd, workStart)
 	for i := 0; i < pathsCount; i++ {
-		src := rand.IntN(len(busStations))
-		dst := rand.IntN(len(busStations))
-		stationsCount := rand.IntN(10) + 10
+		src := rnd.IntN(len(busStations))
+		dst := rnd.IntN(len(busStations))
+		stationsCount := rnd.IntN(10) + 10
 
-		p := path.NewPath(busStations[src], busStations[dst], inc(), stationsCount, time.Now())
-		dstItems := p.GenDstItems()
+		p := path.NewPath(busStations[src], busStations[dst], inc(), stationsCount, workStart, rnd)
+		dstItems := p.GenDstItems(rnd)
 
 		var rideDur time.Duration
 		for _, item := range dstItems {
@@ -72,7 +115,7 @@ func genTimeTable(
 
 		for i := 0; i < config.C().TimeSeriesPathsCount; i++ {
 			p.StartTime = rndTime()
-			p.ID = uuid.New()
+			p.ID = rnd.UUID()
 			p.EndTime = p.StartTime.Add(rideDur)
 			ttb.AddPath(p, dstItems)
 		}
@@ -89,12 +132,12 @@ func increment() func() int {
 	}
 }
 
-func randTime(from time.Time, to time.Time) func() time.Time {
+func randTime(rnd *random.Source, from time.Time, to time.Time) func() time.Time {
 	fromH := from.Hour()
 	toH := to.Hour()
 	minArr := []int{5, 10, 15, 20, 25, 30, 35, 45, 50, 55, 0}
+	year, month, day := from.Date()
 	return func() time.Time {
-		year, month, day := clock.C().Now().Date()
-		return time.Date(year, month, day, rand.IntN(fromH-toH)+toH, minArr[rand.IntN(len(minArr))], 0, 0, time.Local)
+		return time.Date(year, month, day, rnd.IntN(fromH-toH)+toH, minArr[rnd.IntN(len(minArr))], 0, 0, time.UTC)
 	}
 }
@@ -0,0 +1,60 @@
+package scene
+
+import (
+	"course/pkg/driver"
+	"course/pkg/path"
+	"course/pkg/random"
+	"github.com/google/uuid"
+	"maps"
+	"testing"
+	"time"
+)
+
+// одно зерно дает одну и ту же сцену и одни и те же идентификаторы
+// нанятых водителей и автобусов
+func TestGenSceneRepeatsBySeed(t *testing.T) {
+	ttb1, depots1 := GenScene(random.New(7))
+	ttb2, depots2 := GenScene(random.New(7))
+	dhb1, bsb1 := depots1.Pool()
+	dhb2, bsb2 := depots2.Pool()
+
+	paths := func(ps map[uuid.UUID]path.Path) map[uuid.UUID][2]time.Time {
+		res := make(map[uuid.UUID][2]time.Time)
+		for id, p := range ps {
+			res[id] = [2]time.Time{p.StartTime, p.EndTime}
+		}
+		return res
+	}
+	all := func(path.Path) bool { return true }
+	p1, p2 := paths(ttb1.Build().GetEach(all)), paths(ttb2.Build().GetEach(all))
+	if len(p1) == 0 || !maps.Equal(p1, p2) {
+		t.Errorf("timetables differ: %d and %d paths", len(p1), len(p2))
+	}
+	for _, ts := range p1 {
+		if y, m, d := ts[0].Date(); time.Date(y, m, d, 0, 0, 0, 0, time.UTC) != Date() {
+			t.Fatalf("path starts at %s, want the scene date %s", ts[0], Date())
+		}
+	}
+
+	dh1, dh2 := dhb1.Build(), dhb2.Build()
+	types := func(ds map[uuid.UUID]driver.Driver) map[uuid.UUID]driver.DriverType {
+		res := make(map[uuid.UUID]driver.DriverType)
+		for id, d := range ds {
+			res[id] = d.Type()
+		}
+		return res
+	}
+	if !maps.Equal(types(dh1.Drivers()), types(dh2.Drivers())) {
+		t.Error("drivers differ")
+	}
+	if dh1.NewID() != dh2.NewID() {
+		t.Error("hired drivers get different IDs")
+	}
+	bs1, bs2 := bsb1.Build(), bsb2.Build()
+	if !maps.Equal(bs1.Buses(), bs2.Buses()) {
+		t.Error("buses differ")
+	}
+	if bs1.NewID() != bs2.NewID() {
+		t.Error("new buses get different IDs")
+	}
+}
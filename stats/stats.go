@@ -1,11 +1,14 @@
 package stats
 
 import (
+	"course/cost"
+	"course/optimizer/vsp"
+	"course/payroll"
+	"course/pkg/depot"
 	"course/pkg/driver"
 	"course/pkg/driverhub"
-	"course/pkg/path"
 	"course/pkg/station"
-	"course/pkg/timetable/ttv1"
+	"course/pkg/timetable"
 	"fmt"
 	"math"
 	"os"
@@ -14,11 +17,14 @@ import (
 )
 
 type DriversStats struct {
+	// зерно, из которого выведены зерна всех экспериментов
+	seed uint64
 	exps map[string][]stat
 }
 
-func NewDriversStats() *DriversStats {
+func NewDriversStats(seed uint64) *DriversStats {
 	return &DriversStats{
+		seed: seed,
 		exps: make(map[string][]stat),
 	}
 }
@@ -28,15 +34,43 @@ type stat struct {
 	busCount            float64
 	averagePathOnDriver float64
 	averagePathOnBus    float64
-	// отношение DriverA к всем остальным
+	// доля водителей первого типа из конфигурации
 	drvsDistribution float64
+	// число водителей по типам, в порядке driver.Types()
+	byType []float64
+	// стоимость расписания по общей модели cost.M()
+	objective float64
+	// минимально возможное число автобусов для расписания
+	busLowerBound float64
+	// машинные часы автобусов вместе с выездами из депо и возвратами
+	vehicleHours float64
+	// фонд оплаты труда водителей по ведомости
+	labourCost float64
+	// полная стоимость расписания: оплата труда, парк и машинные часы
+	scheduleCost float64
+	// разбивка по депо, в порядке депо сцены
+	depots []depotStat
+	// зерно эксперимента, по которому его можно повторить
+	seed uint64
+}
+
+// depotStat - часть результата, приходящаяся на одно депо
+type depotStat struct {
+	name         string
+	busCount     float64
+	driversCount float64
+	pathCount    float64
+	vehicleHours float64
 }
 
 func (ds *DriversStats) Collect(
-	tt *ttv1.TimeTable,
+	tt timetable.Timetable,
 	dh *driverhub.DriverHub,
 	bs *station.BusStation,
+	depots []depot.Depot,
+	pr payroll.Payroll,
 	optimizer string,
+	seed uint64,
 ) {
 	if ds.exps[optimizer] == nil {
 		ds.exps[optimizer] = make([]stat, 0)
@@ -46,27 +80,54 @@ func (ds *DriversStats) Collect(
 	s.driversCount = float64(len(dh.Drivers()))
 
 	for _, d := range dh.Drivers() {
-		s.averagePathOnDriver += float64(len(tt.GetEach(func(p path.Path) bool { return p.DriverID == d.ID() })))
+		s.averagePathOnDriver += float64(len(tt.DriverPaths(d.ID())))
 	}
 	s.averagePathOnDriver /= s.driversCount
 
-	adrvs := float64(len(dh.GetEach(func(d driver.Driver) bool { return d.Type() == driver.DriverA })))
-	s.drvsDistribution = adrvs / s.driversCount
+	for _, spec := range driver.Types() {
+		n := float64(len(dh.GetEach(func(d driver.Driver) bool { return d.Type() == spec.Name })))
+		s.byType = append(s.byType, n)
+	}
+	s.drvsDistribution = s.byType[0] / s.driversCount
 
 	s.busCount = float64(len(bs.Buses()))
 
 	for _, b := range bs.Buses() {
-		s.averagePathOnBus += float64(len(tt.GetEach(func(p path.Path) bool { return p.BusID == b.ID })))
+		s.averagePathOnBus += float64(len(tt.BusPaths(b.ID)))
 	}
 
 	s.averagePathOnBus /= float64(s.driversCount)
 
+	s.objective = cost.M().Score(tt, dh, bs).Total()
+	s.busLowerBound = float64(vsp.MinFleet(tt))
+	for _, blk := range bs.Blocks(tt) {
+		s.vehicleHours += blk.Duration().Hours()
+	}
+	w := cost.M().Weights()
+	s.labourCost = pr.Total.Total()
+	s.scheduleCost = s.labourCost + s.busCount*w.Bus + s.vehicleHours*w.VehicleHour
+	for _, d := range depots {
+		dst := depotStat{
+			name:         d.Point.Name,
+			busCount:     float64(len(d.Buses.Buses())),
+			driversCount: float64(len(d.Drivers.Drivers())),
+		}
+		for _, blk := range d.Buses.Blocks(tt) {
+			dst.pathCount += float64(len(blk.Paths))
+			dst.vehicleHours += blk.Duration().Hours()
+		}
+		s.depots = append(s.depots, dst)
+	}
+	s.seed = seed
+
 	ds.exps[optimizer] = append(ds.exps[optimizer], *s)
 }
 
 func (ds *DriversStats) SaveStatistics(filename string) error {
 	var builder strings.Builder
 
+	builder.WriteString(fmt.Sprintf("## Зерно: %d\n\n", ds.seed))
+
 	// Вывод данных по каждой группе экспериментов
 	for optimizer, stats := range ds.exps {
 		builder.WriteString(fmt.Sprintf("### Результаты экспериментов для оптимизатора: %s\n\n", optimizer))
@@ -82,34 +143,71 @@ func (ds *DriversStats) SaveStatistics(filename string) error {
 		builder.WriteString(fmt.Sprintf("- Среднее значение:\n"))
 		builder.WriteString(fmt.Sprintf("  - Drivers Count: %.2f\n", avg.driversCount))
 		builder.WriteString(fmt.Sprintf("  - Bus Count: %.2f\n", avg.busCount))
+		builder.WriteString(fmt.Sprintf("  - Labour Cost: %.2f\n", avg.labourCost))
+		builder.WriteString(fmt.Sprintf("  - Schedule Cost: %.2f\n", avg.scheduleCost))
+		builder.WriteString(fmt.Sprintf("  - Bus Lower Bound: %.2f\n", avg.busLowerBound))
+		builder.WriteString(fmt.Sprintf("  - Vehicle Hours: %.2f\n", avg.vehicleHours))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Driver: %.2f\n", avg.averagePathOnDriver))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Bus: %.2f\n", avg.averagePathOnBus))
-		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n\n", avg.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n", avg.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Objective: %.2f\n\n", avg.objective))
 
 		builder.WriteString(fmt.Sprintf("- Медиана:\n"))
 		builder.WriteString(fmt.Sprintf("  - Drivers Count: %.2f\n", median.driversCount))
 		builder.WriteString(fmt.Sprintf("  - Bus Count: %.2f\n", median.busCount))
+		builder.WriteString(fmt.Sprintf("  - Labour Cost: %.2f\n", median.labourCost))
+		builder.WriteString(fmt.Sprintf("  - Schedule Cost: %.2f\n", median.scheduleCost))
+		builder.WriteString(fmt.Sprintf("  - Bus Lower Bound: %.2f\n", median.busLowerBound))
+		builder.WriteString(fmt.Sprintf("  - Vehicle Hours: %.2f\n", median.vehicleHours))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Driver: %.2f\n", median.averagePathOnDriver))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Bus: %.2f\n", median.averagePathOnBus))
-		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n\n", median.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n", median.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Objective: %.2f\n\n", median.objective))
 
 		builder.WriteString(fmt.Sprintf("- Дисперсия:\n"))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Driver: %.2f\n", variance.averagePathOnDriver))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Bus: %.2f\n", variance.averagePathOnBus))
-		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n\n", variance.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n", variance.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Objective: %.2f\n\n", variance.objective))
 
 		builder.WriteString(fmt.Sprintf("- Стандартное отклонение:\n"))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Driver: %.2f\n", stdDev.averagePathOnDriver))
 		builder.WriteString(fmt.Sprintf("  - Avg Path On Bus: %.2f\n", stdDev.averagePathOnBus))
-		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n\n", stdDev.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Drvs Distribution: %.2f\n", stdDev.drvsDistribution))
+		builder.WriteString(fmt.Sprintf("  - Objective: %.2f\n\n", stdDev.objective))
+
+		// Разбивка по типам водителей, средние по экспериментам
+		builder.WriteString("#### По типам водителей (среднее):\n\n")
+		builder.WriteString("| Driver Type | Drivers Count |\n")
+		builder.WriteString("|-------------|---------------|\n")
+		for i, spec := range driver.Types() {
+			var n float64
+			for _, s := range stats {
+				n += s.byType[i]
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %.2f |\n", spec.Name, n/float64(len(stats))))
+		}
+		builder.WriteString("\n")
+
+		// Разбивка по депо, средние по экспериментам
+		if depots := averageDepots(stats); len(depots) > 0 {
+			builder.WriteString("#### По депо (среднее):\n\n")
+			builder.WriteString("| Depot | Bus Count | Drivers Count | Path Count | Vehicle Hours |\n")
+			builder.WriteString("|-------|-----------|---------------|------------|---------------|\n")
+			for _, d := range depots {
+				builder.WriteString(fmt.Sprintf("| %s | %.2f | %.2f | %.2f | %.2f |\n",
+					d.name, d.busCount, d.driversCount, d.pathCount, d.vehicleHours))
+			}
+			builder.WriteString("\n")
+		}
 
 		// Вывод данных по каждому эксперименту
 		builder.WriteString("#### Детализация экспериментов:\n\n")
-		builder.WriteString(fmt.Sprintf("| Experiment | Drivers Count | Bus Count | Avg Path/Driver | Avg Path/Bus | Drvs Distribution |\n"))
-		builder.WriteString(fmt.Sprintf("|------------|---------------|-----------|-----------------|--------------|--------------------|\n"))
+		builder.WriteString(fmt.Sprintf("| Experiment | Drivers Count | Bus Count | Labour Cost | Schedule Cost | Avg Path/Driver | Avg Path/Bus | Drvs Distribution | Objective | Bus Lower Bound | Vehicle Hours | Seed |\n"))
+		builder.WriteString(fmt.Sprintf("|------------|---------------|-----------|-------------|---------------|-----------------|--------------|--------------------|-----------|-----------------|---------------|------|\n"))
 		for i, s := range stats {
-			builder.WriteString(fmt.Sprintf("| %10d | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
-				i+1, s.driversCount, s.busCount, s.averagePathOnDriver, s.averagePathOnBus, s.drvsDistribution))
+			builder.WriteString(fmt.Sprintf("| %10d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %d |\n",
+				i+1, s.driversCount, s.busCount, s.labourCost, s.scheduleCost, s.averagePathOnDriver, s.averagePathOnBus, s.drvsDistribution, s.objective, s.busLowerBound, s.vehicleHours, s.seed))
 		}
 		builder.WriteString("\n\n")
 	}
@@ -138,6 +236,11 @@ func calculateAverage(stats []stat) stat {
 		avg.averagePathOnDriver += s.averagePathOnDriver
 		avg.averagePathOnBus += s.averagePathOnBus
 		avg.drvsDistribution += s.drvsDistribution
+		avg.objective += s.objective
+		avg.busLowerBound += s.busLowerBound
+		avg.vehicleHours += s.vehicleHours
+		avg.labourCost += s.labourCost
+		avg.scheduleCost += s.scheduleCost
 	}
 	n := float64(len(stats))
 	if n > 0 {
@@ -146,10 +249,47 @@ func calculateAverage(stats []stat) stat {
 		avg.averagePathOnDriver /= n
 		avg.averagePathOnBus /= n
 		avg.drvsDistribution /= n
+		avg.objective /= n
+		avg.busLowerBound /= n
+		avg.vehicleHours /= n
+		avg.labourCost /= n
+		avg.scheduleCost /= n
 	}
 	return avg
 }
 
+// averageDepots - средние по депо; депо сопоставляются по имени
+func averageDepots(stats []stat) []depotStat {
+	var (
+		res []depotStat
+		idx = make(map[string]int)
+		n   = make(map[string]float64)
+	)
+	for _, s := range stats {
+		for _, d := range s.depots {
+			i, ok := idx[d.name]
+			if !ok {
+				i = len(res)
+				idx[d.name] = i
+				res = append(res, depotStat{name: d.name})
+			}
+			res[i].busCount += d.busCount
+			res[i].driversCount += d.driversCount
+			res[i].pathCount += d.pathCount
+			res[i].vehicleHours += d.vehicleHours
+			n[d.name]++
+		}
+	}
+	for i := range res {
+		k := n[res[i].name]
+		res[i].busCount /= k
+		res[i].driversCount /= k
+		res[i].pathCount /= k
+		res[i].vehicleHours /= k
+	}
+	return res
+}
+
 func calculateMedian(stats []stat) stat {
 	median := stat{}
 	sort.Slice(stats, func(i, j int) bool {
@@ -189,10 +329,12 @@ func calculateVariance(stats []stat) stat {
 	variance := stat{}
 	for _, s := range stats {
 		variance.averagePathOnDriver += math.Pow(s.averagePathOnDriver-mean.averagePathOnDriver, 2)
+		variance.objective += math.Pow(s.objective-mean.objective, 2)
 	}
 	n := float64(len(stats))
 	if n > 0 {
 		variance.averagePathOnDriver /= n
+		variance.objective /= n
 	}
 	return variance
 }
@@ -200,5 +342,6 @@ func calculateVariance(stats []stat) stat {
 func calculateStdDev(variance stat) stat {
 	stdDev := stat{}
 	stdDev.averagePathOnDriver = math.Sqrt(variance.averagePathOnDriver)
+	stdDev.objective = math.Sqrt(variance.objective)
 	return stdDev
 }
@@ -0,0 +1,64 @@
+package trace
+
+import (
+	"course/optimizer"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Recorder копит события одного запуска оптимизатора для графиков сходимости
+type Recorder struct {
+	mu     sync.Mutex
+	events []optimizer.Event
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Observe - optimizer.Observer, безопасен для одновременного вызова
+func (r *Recorder) Observe(ev optimizer.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+// Save сохраняет события в filename.csv, по строке на событие
+func (r *Recorder) Save(filename string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.Create(fmt.Sprintf("%s.csv", filename))
+	if err != nil {
+		return fmt.Errorf("Ошибка создания файла: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	_ = w.Write([]string{"optimizer", "epoch", "best", "current", "drivers", "buses", "elapsed_ms"})
+	for _, ev := range r.events {
+		_ = w.Write([]string{
+			ev.Optimizer,
+			strconv.Itoa(ev.Epoch),
+			strconv.FormatFloat(ev.Best, 'f', 2, 64),
+			strconv.FormatFloat(ev.Current, 'f', 2, 64),
+			strconv.Itoa(ev.Drivers),
+			strconv.Itoa(ev.Buses),
+			strconv.FormatInt(ev.Elapsed.Milliseconds(), 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Ошибка записи в файл: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package trace
+
+import (
+	"context"
+	"course/optimizer"
+	"course/optimizer/annealing"
+	"course/optimizer/greedy"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"encoding/csv"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// отжиг сообщает о каждом сделанном ходе, лучшая стоимость не растет,
+// а каждое событие становится строкой CSV
+func TestRecorderTracesAnnealing(t *testing.T) {
+	day := time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+	a := path.Point{Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true}
+	b := path.Point{Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true}
+	ttb := ttv1.NewBuilder()
+	for i := 0; i < 6; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		start := day.Add(time.Duration(6+2*i) * time.Hour)
+		ttb.AddPath(path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{from, to},
+			StartTime: start,
+			EndTime:   start.Add(90 * time.Minute),
+		}, nil)
+	}
+
+	rec := NewRecorder()
+	ctx := optimizer.WithObserver(context.Background(), rec.Observe)
+	cfg := annealing.DefaultConfig()
+	cfg.Iterations, cfg.Seed = 50, 1
+	_, err := annealing.New(greedy.NewGreedyOptimizer(), cfg).Optimize(ctx, ttb.Build(),
+		station.NewBusStationBuilder().Build(), driverhub.NewDriverHubBuilder().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Len() == 0 || rec.Len() > cfg.Iterations {
+		t.Fatalf("Len = %d, want 1..%d", rec.Len(), cfg.Iterations)
+	}
+	for i := 1; i < len(rec.events); i++ {
+		prev, ev := rec.events[i-1], rec.events[i]
+		if ev.Epoch <= prev.Epoch || ev.Best > prev.Best {
+			t.Fatalf("event %d = %+v after %+v", i, ev, prev)
+		}
+	}
+
+	name := filepath.Join(t.TempDir(), "trace")
+	if err := rec.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name + ".csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != rec.Len()+1 {
+		t.Errorf("rows = %d, want a header and %d events", len(rows), rec.Len())
+	}
+}
@@ -0,0 +1,269 @@
+package validate
+
+import (
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+)
+
+// Kind - вид нарушения жесткого ограничения
+type Kind int
+
+const (
+	// DriverOverlap - у водителя пересекаются пути
+	DriverOverlap Kind = iota
+	// BusOverlap - у автобуса пересекаются пути
+	BusOverlap
+	// Unassigned - у пути нет водителя или автобуса
+	Unassigned
+	// UnknownDriver - путь назначен водителю, которого нет в DriverHub
+	UnknownDriver
+	// UnknownBus - путь назначен автобусу, которого нет в BusStation
+	UnknownBus
+	// ShiftTooLong - смена водителя длиннее WorkDur
+	ShiftTooLong
+	// MissingRest - водитель ехал дольше ContinuousDur без перерыва RestDur
+	// или не получил положенного перерыва
+	MissingRest
+	// BusLocation - автобус не успевает холостым перегоном от конца
+	// предыдущего пути к началу следующего
+	BusLocation
+	// BusDepot - автобус не может выехать из своего депо к первому пути
+	// или вернуться в него после последнего
+	BusDepot
+	// TooManyBreaks - в смене больше перерывов, чем разрешает тип водителя
+	TooManyBreaks
+	// ShortDailyRest - отдых между сменами короче положенного
+	ShortDailyRest
+	// LabourRule - нарушено другое трудовое правило типа водителя
+	LabourRule
+	// DrivingTime - превышено время вождения за день, неделю или две недели
+	DrivingTime
+)
+
+func (k Kind) String() string {
+	switch k {
+	case DriverOverlap:
+		return "driver overlap"
+	case BusOverlap:
+		return "bus overlap"
+	case Unassigned:
+		return "unassigned"
+	case UnknownDriver:
+		return "unknown driver"
+	case UnknownBus:
+		return "unknown bus"
+	case ShiftTooLong:
+		return "shift too long"
+	case MissingRest:
+		return "missing rest"
+	case TooManyBreaks:
+		return "too many breaks"
+	case ShortDailyRest:
+		return "short daily rest"
+	case LabourRule:
+		return "labour rule"
+	case DrivingTime:
+		return "driving time"
+	case BusLocation:
+		return "bus location"
+	case BusDepot:
+		return "bus depot"
+	default:
+		return "unknown"
+	}
+}
+
+// Violation - одно нарушение. Незаполненные идентификаторы равны uuid.Nil.
+type Violation struct {
+	Kind     Kind
+	PathID   uuid.UUID
+	DriverID uuid.UUID
+	BusID    uuid.UUID
+	Reason   string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Kind, v.Reason)
+}
+
+// Validate проверяет готовое расписание и возвращает все найденные нарушения.
+// Пустой результат означает, что расписание допустимо.
+func Validate(
+	tt timetable.Timetable,
+	dh *driverhub.DriverHub,
+	bs *station.BusStation,
+) []Violation {
+	var vs []Violation
+
+	byDriver := make(map[uuid.UUID][]path.Path)
+	byBus := make(map[uuid.UUID][]path.Path)
+
+	for _, p := range tt.PathsByStart() {
+		if !p.IsPlanned() {
+			vs = append(vs, Violation{
+				Kind:     Unassigned,
+				PathID:   p.ID,
+				DriverID: p.DriverID,
+				BusID:    p.BusID,
+				Reason:   fmt.Sprintf("path %d at %s has no driver or bus", p.Number, p.StartTime.Format("15:04")),
+			})
+		}
+		if p.DriverID != uuid.Nil {
+			byDriver[p.DriverID] = append(byDriver[p.DriverID], p)
+		}
+		if p.BusID != uuid.Nil {
+			byBus[p.BusID] = append(byBus[p.BusID], p)
+		}
+	}
+
+	for _, id := range sortedKeys(byDriver) {
+		ps := byDriver[id]
+		d := dh.GetDriver(id)
+		if d == nil {
+			vs = append(vs, Violation{
+				Kind:     UnknownDriver,
+				DriverID: id,
+				Reason:   fmt.Sprintf("driver %s is not registered", id),
+			})
+			continue
+		}
+		vs = append(vs, validateDriver(d, ps)...)
+	}
+
+	for _, id := range sortedKeys(byBus) {
+		ps := byBus[id]
+		if bs.GetBus(id) == nil {
+			vs = append(vs, Violation{
+				Kind:   UnknownBus,
+				BusID:  id,
+				Reason: fmt.Sprintf("bus %s is not registered", id),
+			})
+			continue
+		}
+		vs = append(vs, validateBus(tt, id, ps)...)
+		if blk := bs.Block(tt, id); !blk.Reachable {
+			vs = append(vs, Violation{
+				Kind:   BusDepot,
+				BusID:  id,
+				Reason: fmt.Sprintf("bus %s cannot get between depot %s and its block", id, blk.Depot.Name),
+			})
+		}
+	}
+
+	return vs
+}
+
+func validateDriver(d driver.Driver, ps []path.Path) []Violation {
+	var vs []Violation
+
+	shift := driver.NewShift(d, ps)
+	sorted := shift.Paths()
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].StartTime.Before(sorted[i-1].EndTime) {
+			vs = append(vs, Violation{
+				Kind:     DriverOverlap,
+				PathID:   sorted[i].ID,
+				DriverID: d.ID(),
+				Reason: fmt.Sprintf(
+					"path %d at %s starts before path %d ends at %s",
+					sorted[i].Number, sorted[i].StartTime.Format("15:04"),
+					sorted[i-1].Number, sorted[i-1].EndTime.Format("15:04"),
+				),
+			})
+		}
+	}
+
+	// пересечения уже разобраны по путям выше
+	for _, v := range shift.Violations() {
+		if v.Rule == (driver.NoOverlap{}).Name() {
+			continue
+		}
+		vs = append(vs, Violation{
+			Kind:     ruleKind(v.Rule),
+			DriverID: d.ID(),
+			Reason:   v.Reason,
+		})
+	}
+
+	return vs
+}
+
+// ruleKind - вид нарушения по имени трудового правила
+func ruleKind(rule string) Kind {
+	switch rule {
+	case driver.NoOverlap{}.Name():
+		return DriverOverlap
+	case driver.MaxSpread{}.Name():
+		return ShiftTooLong
+	case driver.MaxContinuous{}.Name(), driver.MinBreak{}.Name(), driver.EUBreakRule{}.Name():
+		return MissingRest
+	case driver.MaxBreaks{}.Name():
+		return TooManyBreaks
+	case driver.MinDailyRest{}.Name():
+		return ShortDailyRest
+	case driver.EUDailyDrivingRule{}.Name(), driver.EUWeeklyDrivingRule{}.Name(),
+		driver.EUFortnightDrivingRule{}.Name():
+		return DrivingTime
+	default:
+		return LabourRule
+	}
+}
+
+func validateBus(tt timetable.Timetable, busID uuid.UUID, ps []path.Path) []Violation {
+	var vs []Violation
+
+	slices.SortFunc(ps, func(a, b path.Path) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	for i := 1; i < len(ps); i++ {
+		prev, cur := ps[i-1], ps[i]
+		if cur.StartTime.Before(prev.EndTime) {
+			vs = append(vs, Violation{
+				Kind:   BusOverlap,
+				PathID: cur.ID,
+				BusID:  busID,
+				Reason: fmt.Sprintf(
+					"path %d at %s starts before path %d ends at %s",
+					cur.Number, cur.StartTime.Format("15:04"),
+					prev.Number, prev.EndTime.Format("15:04"),
+				),
+			})
+			continue
+		}
+		if !tt.Follows(prev, cur) {
+			vs = append(vs, Violation{
+				Kind:   BusLocation,
+				PathID: cur.ID,
+				BusID:  busID,
+				Reason: fmt.Sprintf(
+					"path %d at %s starts at %s, but path %d ended at %s at %s and the bus cannot deadhead in time",
+					cur.Number, cur.StartTime.Format("15:04"), cur.Points[0].Name,
+					prev.Number, prev.EndTime.Format("15:04"), prev.Points[len(prev.Points)-1].Name,
+				),
+			})
+		}
+	}
+
+	return vs
+}
+
+// sortedKeys - ключи в постоянном порядке, чтобы список нарушений
+// не зависел от порядка обхода map
+func sortedKeys(m map[uuid.UUID][]path.Path) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return ids
+}
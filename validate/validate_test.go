@@ -0,0 +1,161 @@
+package validate
+
+import (
+	"course/pkg/bus"
+	"course/pkg/driver"
+	"course/pkg/driverhub"
+	"course/pkg/path"
+	"course/pkg/station"
+	"course/pkg/timetable/ttv1"
+	"github.com/google/uuid"
+	"slices"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, time.November, 25, 0, 0, 0, 0, time.UTC)
+
+// конечные A и B
+var ends = map[byte]path.Point{
+	'A': {Id: uuid.UUID{'A'}, Name: "A", IsBusStation: true},
+	'B': {Id: uuid.UUID{'B'}, Name: "B", IsBusStation: true},
+}
+
+// trip - путь из from в to с часа start до часа end. drv и bus - номер
+// водителя и автобуса сцены с единицы, 0 - не назначен, -1 - назначен
+// тому, кого нет в штате или парке.
+type trip struct {
+	from, to   byte
+	start, end int
+	drv, bus   int
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		trips []trip
+		want  []Kind
+	}{
+		{"valid", []trip{{'A', 'B', 6, 8, 1, 1}, {'B', 'A', 9, 10, 1, 1}}, nil},
+		{"driver overlap", []trip{{'A', 'B', 6, 8, 1, 1}, {'A', 'B', 7, 9, 1, 2}}, []Kind{DriverOverlap}},
+		{"bus overlap", []trip{{'A', 'B', 6, 8, 1, 1}, {'A', 'B', 7, 9, 2, 1}}, []Kind{BusOverlap}},
+		{"unassigned", []trip{{'A', 'B', 6, 8, 1, 0}}, []Kind{Unassigned}},
+		{"unknown driver", []trip{{'A', 'B', 6, 8, -1, 1}}, []Kind{UnknownDriver}},
+		{"unknown bus", []trip{{'A', 'B', 6, 8, 1, -1}}, []Kind{UnknownBus}},
+		{"shift too long", []trip{{'A', 'B', 6, 8, 1, 1}, {'B', 'A', 15, 17, 1, 1}}, []Kind{ShiftTooLong}},
+		{"missing rest", []trip{{'A', 'B', 6, 11, 1, 1}}, []Kind{MissingRest}},
+		{"too many breaks", []trip{{'A', 'B', 6, 7, 1, 1}, {'B', 'A', 8, 9, 1, 1}, {'A', 'B', 10, 11, 1, 1}},
+			[]Kind{TooManyBreaks}},
+		// девять часов - уже отдых между сменами, но короче положенного
+		{"short daily rest", []trip{{'A', 'B', 6, 8, 1, 1}, {'B', 'A', 17, 19, 1, 1}}, []Kind{ShortDailyRest}},
+		// между путями автобус успевает перегоном из B в A
+		{"bus deadhead in time", []trip{{'A', 'B', 6, 8, 1, 1}, {'A', 'B', 9, 10, 1, 1}}, nil},
+		{"bus location", []trip{{'A', 'B', 6, 8, 1, 1}, {'A', 'B', 8, 10, 1, 1}}, []Kind{BusLocation}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Kind
+			for _, v := range validate(tc.trips, path.Point{}) {
+				got = append(got, v.Kind)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Validate = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleKind(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Kind
+	}{
+		{driver.NoOverlap{}.Name(), DriverOverlap},
+		{driver.MaxSpread{}.Name(), ShiftTooLong},
+		{driver.MaxContinuous{}.Name(), MissingRest},
+		{driver.MinBreak{}.Name(), MissingRest},
+		{driver.MaxBreaks{}.Name(), TooManyBreaks},
+		{driver.MinDailyRest{}.Name(), ShortDailyRest},
+		{driver.EUBreakRule{}.Name(), MissingRest},
+		{driver.EUDailyDrivingRule{}.Name(), DrivingTime},
+		{driver.EUWeeklyDrivingRule{}.Name(), DrivingTime},
+		{driver.EUFortnightDrivingRule{}.Name(), DrivingTime},
+		{"some_other_rule", LabourRule},
+	}
+	for _, tc := range tests {
+		if got := ruleKind(tc.rule); got != tc.want {
+			t.Errorf("ruleKind(%q) = %v, want %v", tc.rule, got, tc.want)
+		}
+	}
+}
+
+func TestValidateBusDepot(t *testing.T) {
+	trips := []trip{{'A', 'B', 6, 8, 1, 1}, {'B', 'A', 9, 10, 1, 1}}
+	tests := []struct {
+		name  string
+		depot path.Point
+		want  []Kind
+	}{
+		{"no depot", path.Point{}, nil},
+		{"depot at an end", ends['A'], nil},
+		// в точку C не ведет ни один перегон
+		{"unreachable depot", path.Point{Id: uuid.UUID{'C'}, Name: "C", IsBusStation: true}, []Kind{BusDepot}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Kind
+			for _, v := range validate(trips, tc.depot) {
+				got = append(got, v.Kind)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Validate = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// validate собирает сцену из trips с двумя водителями типа A
+// и двумя автобусами с депо depot и проверяет ее
+func validate(trips []trip, depot path.Point) []Violation {
+	drvs := []driver.Driver{driver.New(uuid.UUID{0xd1}, "A"), driver.New(uuid.UUID{0xd2}, "A")}
+	buses := []*bus.Bus{bus.NewBus(uuid.UUID{0xb1}), bus.NewBus(uuid.UUID{0xb2})}
+	dhb := driverhub.NewDriverHubBuilder()
+	for _, d := range drvs {
+		dhb.AddDriver(d)
+	}
+	bsb := station.NewBusStationBuilder()
+	bsb.SetDepot(depot)
+	for _, b := range buses {
+		bsb.AddBus(b)
+	}
+
+	ttb := ttv1.NewBuilder()
+	ps := make([]path.Path, len(trips))
+	for i, tr := range trips {
+		ps[i] = path.Path{
+			ID:        uuid.UUID{byte(i + 1)},
+			Number:    i + 1,
+			Points:    []path.Point{ends[tr.from], ends[tr.to]},
+			StartTime: day.Add(time.Duration(tr.start) * time.Hour),
+			EndTime:   day.Add(time.Duration(tr.end) * time.Hour),
+		}
+		ttb.AddPath(ps[i], nil)
+	}
+	tt := ttb.Build()
+	for i, tr := range trips {
+		switch {
+		case tr.drv > 0:
+			tt.AssignDriverToPath(ps[i].ID, drvs[tr.drv-1].ID())
+		case tr.drv < 0:
+			tt.AssignDriverToPath(ps[i].ID, uuid.UUID{0xdd})
+		}
+		switch {
+		case tr.bus > 0:
+			tt.AssignBusToPath(ps[i].ID, buses[tr.bus-1].ID)
+		case tr.bus < 0:
+			tt.AssignBusToPath(ps[i].ID, uuid.UUID{0xbb})
+		}
+	}
+	return Validate(tt, dhb.Build(), bsb.Build())
+}